@@ -19,24 +19,158 @@ package cfg
 import (
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 	"os"
 	"path"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type Hook struct {
-	Command string   `json:"command"`
-	Args    []string `json:"args"`
-	Script  string   `json:"script"`
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	Script  string   `yaml:"script"`
+
+	// Shell is the interpreter for a script hook (bash, sh or python).
+	Shell string `yaml:"shell"`
+
+	// WorkingDir is the directory the hook runs in. Relative paths are
+	// resolved against the repository root.
+	WorkingDir string `yaml:"workingDir"`
+
+	// Env holds additional environment variables for the hook.
+	Env map[string]string `yaml:"env"`
+
+	// Timeout bounds each attempt of the hook, e.g. "30s". Zero means no timeout.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retries is the number of additional attempts made if the hook fails.
+	Retries int `yaml:"retries"`
+
+	// ContinueOnError logs a failure of the hook instead of aborting.
+	ContinueOnError bool `yaml:"continueOnError"`
+
+	// Template controls whether the command, args and script are rendered
+	// as Go templates. It defaults to true; set it to false to pass text
+	// containing '{{', such as a 'docker inspect --format' argument, as is.
+	Template *bool `yaml:"template"`
+}
+
+// IsTemplate returns true if the hook is rendered as a Go template.
+func (h Hook) IsTemplate() bool {
+	return h.Template == nil || *h.Template
+}
+
+// CommandHooks holds the hooks attached to a specific command.
+type CommandHooks struct {
+	Before []Hook `yaml:"before"`
+	After  []Hook `yaml:"after"`
+}
+
+// ChangelogConfig configures how changelog sections are rendered.
+type ChangelogConfig struct {
+	// Template is an inline Go template used to render each release.
+	Template string `yaml:"template"`
+
+	// TemplateFile is the path to a file containing a Go template used to
+	// render each release. Relative paths are resolved against the repository root.
+	TemplateFile string `yaml:"templateFile"`
+
+	// Format is the changelog format: markdown, asciidoc, rst, debian or rpm.
+	// If not set, it is detected from the name of the changelog file.
+	Format string `yaml:"format"`
+
+	// Package holds the package details used by the debian and rpm formats.
+	Package PackageConfig `yaml:"package"`
+
+	// EntryOrder is the order of the entries within each section:
+	// alphabetical (the default), chronological, reverse-chronological or scope.
+	EntryOrder string `yaml:"entryOrder"`
+
+	// Entries configures how the text of each entry is normalised.
+	Entries EntryConfig `yaml:"entries"`
+
+	// Contributors configures the list of contributors added to each release.
+	Contributors ContributorsConfig `yaml:"contributors"`
+
+	// FragmentsDir is the directory of changelog fragment files, relative
+	// to the repository root. Defaults to '.changes'.
+	FragmentsDir string `yaml:"fragmentsDir"`
+}
+
+// ContributorsConfig configures the list of contributors added to each
+// release, made up of the commit authors and co-authors.
+type ContributorsConfig struct {
+	// Enabled adds a Contributors list to each release.
+	Enabled bool `yaml:"enabled"`
+
+	// Exclude holds patterns matched against the name and email of each
+	// contributor, such as '\[bot\]$'. Matching contributors are left out.
+	Exclude []string `yaml:"exclude"`
+}
+
+// EntryConfig configures how commit messages are normalised into
+// changelog entries. All options are disabled by default, so entries
+// are the raw commit subject.
+type EntryConfig struct {
+	// StripType removes the conventional commit type prefix, such as 'feat:'.
+	StripType bool `yaml:"stripType"`
+
+	// Capitalise upper-cases the first letter of the description.
+	Capitalise bool `yaml:"capitalise"`
+
+	// HighlightScope renders the scope as a bold prefix, such as '**api:**'.
+	HighlightScope bool `yaml:"highlightScope"`
+
+	// TrimPunctuation removes trailing punctuation, such as a full stop.
+	TrimPunctuation bool `yaml:"trimPunctuation"`
+
+	// TrimPullRequest removes a trailing pull request reference, such as '(#123)'.
+	TrimPullRequest bool `yaml:"trimPullRequest"`
+}
+
+// PackageConfig holds the package details written to Debian and RPM changelogs.
+type PackageConfig struct {
+	// Name is the package name. Defaults to the name of the repository directory.
+	Name string `yaml:"name"`
+
+	// Distribution is the Debian distribution, such as 'unstable' (the default).
+	Distribution string `yaml:"distribution"`
+
+	// Urgency is the Debian upload urgency, such as 'medium' (the default).
+	Urgency string `yaml:"urgency"`
+
+	// Maintainer is the name and email of the maintainer, such as
+	// 'Jane Doe <jane@example.com>'. Defaults to the author of the latest commit.
+	Maintainer string `yaml:"maintainer"`
 }
 
 type SinceConfig struct {
-	Before        []Hook   `yaml:"before"`
-	After         []Hook   `yaml:"after"`
-	RequireBranch string   `yaml:"requireBranch"`
-	Ignore        []string `yaml:"ignore"`
+	Before         []Hook                  `yaml:"before"`
+	AfterChangelog []Hook                  `yaml:"afterChangelog"`
+	AfterCommit    []Hook                  `yaml:"afterCommit"`
+	AfterTag       []Hook                  `yaml:"afterTag"`
+	AfterPush      []Hook                  `yaml:"afterPush"`
+	After          []Hook                  `yaml:"after"`
+	OnFailure      []Hook                  `yaml:"onFailure"`
+	Commands       map[string]CommandHooks `yaml:"commands"`
+	Changelog      ChangelogConfig         `yaml:"changelog"`
+	RequireBranch  string                  `yaml:"requireBranch"`
+	Ignore         []string                `yaml:"ignore"`
 }
 
+// Commands that support hooks under the `commands` key.
+const (
+	CommandChangelogGenerate = "changelog generate"
+	CommandChangelogUpdate   = "changelog update"
+	CommandProjectRelease    = "project release"
+)
+
+// HookCommands lists the commands that hooks can be attached to.
+var HookCommands = []string{CommandChangelogGenerate, CommandChangelogUpdate, CommandProjectRelease}
+
 const DefaultConfigFile = "since.yaml"
 
 // SupportedConfigFiles lists the config file names since recognises,
@@ -76,5 +210,18 @@ func loadConfig(configPath string) (SinceConfig, error) {
 	if err != nil {
 		return SinceConfig{}, fmt.Errorf("error: %v", err)
 	}
+	for command := range config.Commands {
+		if !slices.Contains(HookCommands, command) {
+			return SinceConfig{}, fmt.Errorf("error: unsupported command '%s' for hooks, must be one of: %s", command, strings.Join(HookCommands, ", "))
+		}
+	}
+	if config.Changelog.Template != "" && config.Changelog.TemplateFile != "" {
+		return SinceConfig{}, fmt.Errorf("error: changelog template and templateFile cannot both be set")
+	}
+	for _, exclude := range config.Changelog.Contributors.Exclude {
+		if _, err := regexp.Compile(exclude); err != nil {
+			return SinceConfig{}, fmt.Errorf("error: invalid contributor exclude pattern %q: %v", exclude, err)
+		}
+	}
 	return config, nil
 }
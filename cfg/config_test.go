@@ -21,6 +21,7 @@ import (
 	"path"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_loadConfig(t *testing.T) {
@@ -147,3 +148,133 @@ func TestLoadConfig_yamlTakesPrecedenceOverYml(t *testing.T) {
 		t.Errorf("LoadConfig() got = %v, want %v", got, want)
 	}
 }
+
+func TestLoadConfig_hookOptions(t *testing.T) {
+	dir := t.TempDir()
+	content := `after:
+  - script: ./notify.py
+    shell: python
+    workingDir: scripts
+    timeout: 30s
+    retries: 2
+    continueOnError: true
+    template: false
+    env:
+      CHANNEL: releases
+`
+	if err := os.WriteFile(path.Join(dir, DefaultConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	template := false
+	want := SinceConfig{After: []Hook{{
+		Script:          "./notify.py",
+		Shell:           "python",
+		WorkingDir:      "scripts",
+		Env:             map[string]string{"CHANNEL": "releases"},
+		Timeout:         30 * time.Second,
+		Retries:         2,
+		ContinueOnError: true,
+		Template:        &template,
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadConfig() got = %v, want %v", got, want)
+	}
+}
+
+func TestLoadConfig_commandHooks(t *testing.T) {
+	dir := t.TempDir()
+	content := `commands:
+  changelog update:
+    after:
+      - command: make
+        args: ["docs"]
+`
+	if err := os.WriteFile(path.Join(dir, DefaultConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := SinceConfig{Commands: map[string]CommandHooks{
+		CommandChangelogUpdate: {After: []Hook{{Command: "make", Args: []string{"docs"}}}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadConfig() got = %v, want %v", got, want)
+	}
+}
+
+func TestLoadConfig_unsupportedCommandHooks(t *testing.T) {
+	dir := t.TempDir()
+	content := "commands:\n  changelog extract:\n    after:\n      - command: echo\n"
+	if err := os.WriteFile(path.Join(dir, DefaultConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(dir); err == nil {
+		t.Error("LoadConfig() expected error for unsupported command")
+	}
+}
+
+func TestLoadConfig_changelogTemplate(t *testing.T) {
+	dir := t.TempDir()
+	content := "changelog:\n  templateFile: .github/release.tmpl\n"
+	if err := os.WriteFile(path.Join(dir, DefaultConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got.Changelog.TemplateFile != ".github/release.tmpl" {
+		t.Errorf("LoadConfig() templateFile = %v, want .github/release.tmpl", got.Changelog.TemplateFile)
+	}
+}
+
+func TestLoadConfig_changelogTemplateAndFile(t *testing.T) {
+	dir := t.TempDir()
+	content := "changelog:\n  template: '{{ .Version }}'\n  templateFile: release.tmpl\n"
+	if err := os.WriteFile(path.Join(dir, DefaultConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(dir); err == nil {
+		t.Error("LoadConfig() expected error when both template and templateFile are set")
+	}
+}
+
+func TestLoadConfig_changelogEntries(t *testing.T) {
+	dir := t.TempDir()
+	content := "changelog:\n  entries:\n    stripType: true\n    capitalise: true\n    highlightScope: true\n    trimPunctuation: true\n    trimPullRequest: true\n"
+	if err := os.WriteFile(path.Join(dir, DefaultConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := EntryConfig{StripType: true, Capitalise: true, HighlightScope: true, TrimPunctuation: true, TrimPullRequest: true}
+	if got.Changelog.Entries != want {
+		t.Errorf("LoadConfig() entries = %+v, want %+v", got.Changelog.Entries, want)
+	}
+}
+
+func TestLoadConfig_invalidContributorExclude(t *testing.T) {
+	dir := t.TempDir()
+	content := "changelog:\n  contributors:\n    enabled: true\n    exclude: ['[bot']\n"
+	if err := os.WriteFile(path.Join(dir, DefaultConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(dir); err == nil {
+		t.Error("LoadConfig() expected error for an invalid contributor exclude pattern")
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"embed"
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/aggregate.*.tmpl
+var aggregateTemplates embed.FS
+
+// Manifest lists the repositories whose changes are combined into an
+// aggregate release document, such as for a product that ships from
+// several repositories.
+type Manifest struct {
+	// Title is the heading of the document, if any.
+	Title string `yaml:"title"`
+
+	Repositories []ManifestRepository `yaml:"repositories"`
+}
+
+// ManifestRepository is a repository in the manifest, with the tag or
+// range of tags whose changes are included.
+type ManifestRepository struct {
+	// Name is the heading of the repository, which defaults to the
+	// base name of its path.
+	Name string `yaml:"name"`
+
+	// Path is the path to the repository, relative to the manifest file.
+	Path string `yaml:"path"`
+
+	// Tag includes the changes released in this tag.
+	Tag string `yaml:"tag"`
+
+	// From includes the changes after this tag.
+	From string `yaml:"from"`
+
+	// To includes the changes up to and including this tag, or HEAD if
+	// it is not set.
+	To string `yaml:"to"`
+}
+
+// LoadManifest reads the manifest file, resolving the repository paths
+// against the directory of the manifest.
+func LoadManifest(manifestFile string) (Manifest, error) {
+	content, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifest: %s: %w", manifestFile, err)
+	}
+	if len(manifest.Repositories) == 0 {
+		return Manifest{}, fmt.Errorf("no repositories in manifest: %s", manifestFile)
+	}
+
+	names := make(map[string]bool)
+	for i := range manifest.Repositories {
+		repo := &manifest.Repositories[i]
+		if repo.Path == "" {
+			return Manifest{}, fmt.Errorf("repository %d in manifest has no path", i+1)
+		}
+		if repo.Tag != "" && (repo.From != "" || repo.To != "") {
+			return Manifest{}, fmt.Errorf("repository %s in manifest sets both a tag and a range", repo.Path)
+		}
+		if !filepath.IsAbs(repo.Path) {
+			repo.Path = filepath.Join(filepath.Dir(manifestFile), repo.Path)
+		}
+		if repo.Name == "" {
+			absPath, err := filepath.Abs(repo.Path)
+			if err != nil {
+				return Manifest{}, err
+			}
+			repo.Name = filepath.Base(absPath)
+		}
+		if names[repo.Name] {
+			return Manifest{}, fmt.Errorf("duplicate repository name in manifest: %s", repo.Name)
+		}
+		names[repo.Name] = true
+	}
+	return manifest, nil
+}
+
+// AggregateChangelog returns a release document combining the changes of
+// each repository in the manifest, grouped by repository, then by section.
+// Each repository has its own heading, one level below the title. Its
+// entries are built using its own since.yaml configuration, but rendered
+// with the built-in aggregate template for the format, so that every
+// repository has the same heading levels.
+func AggregateChangelog(commitCfg vcs.CommitConfig, manifest Manifest, format Format, orderBy vcs.TagOrderBy) (string, error) {
+	var title string
+	switch format {
+	case FormatMarkdown:
+		title = "# " + manifest.Title
+	case FormatAsciiDoc:
+		title = "= " + manifest.Title
+	case FormatRST:
+		title = manifest.Title + "\n" + strings.Repeat("=", len(manifest.Title))
+	default:
+		return "", fmt.Errorf("unsupported format for an aggregate changelog: %s", format)
+	}
+	tmpl, err := loadAggregateTemplate(format)
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	if manifest.Title != "" {
+		parts = append(parts, title)
+	}
+	for _, repo := range manifest.Repositories {
+		rendered, err := renderRepository(commitCfg, repo, format, orderBy, tmpl)
+		if err != nil {
+			return "", fmt.Errorf("failed to render changes of repository %s: %w", repo.Name, err)
+		}
+		parts = append(parts, rendered)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// loadAggregateTemplate returns the built-in template that renders the
+// changes of each repository in the format.
+func loadAggregateTemplate(format Format) (*template.Template, error) {
+	content, err := aggregateTemplates.ReadFile("templates/aggregate." + string(format) + ".tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("no built-in aggregate template for format: %s", format)
+	}
+	tmpl, err := template.New(string(format)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse aggregate template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderRepository renders the changes in the tag or range of the
+// repository as a single release, headed by the repository name. The
+// changes in a tag are those since the tag before it, in the given order.
+func renderRepository(commitCfg vcs.CommitConfig, repo ManifestRepository, format Format, orderBy vcs.TagOrderBy, tmpl *template.Template) (string, error) {
+	config, err := cfg.LoadConfig(repo.Path)
+	if err != nil {
+		return "", err
+	}
+
+	beforeTag, afterTag := repo.To, repo.From
+	if repo.Tag != "" {
+		// only the changes since the previous tag are released in the tag
+		beforeTag = repo.Tag
+		if afterTag, err = vcs.GetPreviousTag(repo.Path, repo.Tag, orderBy); err != nil {
+			return "", fmt.Errorf("failed to find the tag before %s: %w", repo.Tag, err)
+		}
+	}
+	commits, _, err := vcs.FetchCommitsByTag(config, commitCfg, repo.Path, beforeTag, afterTag)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch commit messages from repo: %s: %v", repo.Path, err)
+	}
+
+	// the commits of all the tags in the range are listed together
+	combined := vcs.TagCommits{TagMeta: vcs.TagMeta{Name: vcs.UnreleasedVersionName}}
+	for i, tagCommits := range *commits {
+		if repo.Tag != "" && tagCommits.Name != repo.Tag {
+			break
+		}
+		if i == 0 {
+			combined.Date = tagCommits.Date
+		}
+		combined.Commits = append(combined.Commits, tagCommits.Commits...)
+		combined.Details = append(combined.Details, tagCommits.CommitDetails()...)
+		if repo.Tag != "" {
+			break
+		}
+	}
+	logrus.Debugf("aggregating %d commits from repository %s", len(combined.Commits), repo.Name)
+
+	heading := repo.Name
+	switch {
+	case repo.Tag != "":
+		heading += " " + repo.Tag
+	case repo.From == "" && repo.To != "":
+		heading += " " + repo.To
+	case repo.From != "":
+		to := repo.To
+		if to == "" {
+			to = "HEAD"
+		}
+		heading += fmt.Sprintf(" %s...%s", repo.From, to)
+	}
+
+	renderer, err := NewRenderer(config, repo.Path, format)
+	if err != nil {
+		return "", err
+	}
+	renderer.Template = tmpl
+	// a range ending at HEAD has not been released, so has no date
+	released := beforeTag != "" && !combined.Date.IsZero()
+	return renderer.Render(&[]vcs.TagCommits{combined}, true, released, heading)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/vcs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []ManifestRepository
+		wantErr  bool
+	}{
+		{
+			name: "relative paths and default names",
+			manifest: `repositories:
+  - path: api
+    tag: 1.0.0
+  - name: web
+    path: /src/frontend
+    from: 2.0.0
+`,
+			want: []ManifestRepository{
+				{Name: "api", Path: "api", Tag: "1.0.0"},
+				{Name: "web", Path: "/src/frontend", From: "2.0.0"},
+			},
+		},
+		{
+			name:     "no repositories",
+			manifest: "title: Product\n",
+			wantErr:  true,
+		},
+		{
+			name:     "missing path",
+			manifest: "repositories:\n  - tag: 1.0.0\n",
+			wantErr:  true,
+		},
+		{
+			name:     "tag and range",
+			manifest: "repositories:\n  - path: api\n    tag: 1.0.0\n    from: 0.9.0\n",
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate name",
+			manifest: "repositories:\n  - path: a/api\n  - path: b/api\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			manifestFile := filepath.Join(dir, "manifest.yaml")
+			if err := os.WriteFile(manifestFile, []byte(tt.manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadManifest(manifestFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Repositories) != len(tt.want) {
+				t.Fatalf("LoadManifest() = %+v, want %+v", got.Repositories, tt.want)
+			}
+			for i, want := range tt.want {
+				if !filepath.IsAbs(want.Path) {
+					want.Path = filepath.Join(dir, want.Path)
+				}
+				if got.Repositories[i] != want {
+					t.Errorf("LoadManifest() repository %d = %+v, want %+v", i, got.Repositories[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateChangelog(t *testing.T) {
+	apiRepo := createTestRepo(t)
+	webRepo := createTestRepo(t)
+	commitChange(t, webRepo, "README.md", "fix\r\n", "fix: correct layout", time.Now())
+	today := time.Now().Format("2006-01-02")
+
+	tests := []struct {
+		name     string
+		manifest Manifest
+		format   Format
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "tag and range",
+			manifest: Manifest{
+				Title: "Product 2.0",
+				Repositories: []ManifestRepository{
+					{Name: "api", Path: apiRepo, Tag: "0.1.0"},
+					{Name: "web", Path: webRepo, From: "0.0.1"},
+				},
+			},
+			format: FormatMarkdown,
+			want: fmt.Sprintf(`# Product 2.0
+
+## api 0.1.0 - %[1]v
+### Added
+- feat: second update
+
+## web 0.0.1...HEAD
+### Added
+- feat: second update
+
+### Fixed
+- fix: correct layout`, today),
+		},
+		{
+			name: "whole history up to a tag",
+			manifest: Manifest{
+				Repositories: []ManifestRepository{
+					{Name: "api", Path: apiRepo, To: "0.1.0"},
+				},
+			},
+			format: FormatAsciiDoc,
+			want: fmt.Sprintf(`== api 0.1.0 - %[1]v
+=== Added
+* feat: first update
+* feat: second update`, today),
+		},
+		{
+			name: "unsupported format",
+			manifest: Manifest{
+				Repositories: []ManifestRepository{{Name: "api", Path: apiRepo, Tag: "0.1.0"}},
+			},
+			format:  FormatDebian,
+			wantErr: true,
+		},
+		{
+			name: "missing tag",
+			manifest: Manifest{
+				Repositories: []ManifestRepository{{Name: "api", Path: apiRepo, Tag: "9.9.9"}},
+			},
+			format:  FormatMarkdown,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AggregateChangelog(vcs.CommitConfig{UniqueOnly: true}, tt.manifest, tt.format, vcs.TagOrderSemver)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AggregateChangelog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AggregateChangelog() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,25 +20,45 @@ import (
 	_ "embed"
 	"fmt"
 	"github.com/release-tools/since/cfg"
-	"github.com/release-tools/since/convcommits"
 	"github.com/release-tools/since/semver"
 	"github.com/release-tools/since/vcs"
-	"github.com/sirupsen/logrus"
-	"golang.org/x/exp/maps"
-	"sort"
 	"strings"
+	"text/template"
 )
 
 type Sections struct {
 	Boilerplate string
 	Body        string
+
+	// Unreleased is the Unreleased section omitted from the body, if any.
+	Unreleased *Release
 }
 
 //go:embed templates/changelog.md
 var changelogTemplate string
 
+//go:embed templates/changelog.adoc
+var asciiDocChangelogTemplate string
+
+//go:embed templates/changelog.rst
+var rstChangelogTemplate string
+
+// BreakingChangesSectionName is the section for breaking changes, which
+// is rendered before the other sections of a release.
+const BreakingChangesSectionName = "⚠ Breaking Changes"
+
+// ContributorsSectionName is the section listing the contributors to a
+// release, which is rendered after the other sections.
+const ContributorsSectionName = "Contributors"
+
 var sectionMap map[string][]string
 
+// defaultRenderer renders releases using the built-in Markdown template.
+var defaultRenderer = &Renderer{
+	Format:   FormatMarkdown,
+	Template: template.Must(loadTemplate(cfg.SinceConfig{}, "", FormatMarkdown)),
+}
+
 func init() {
 	sectionMap = make(map[string][]string)
 	sectionMap["Added"] = []string{"feat"}
@@ -47,117 +67,45 @@ func init() {
 }
 
 // RenderCommits takes a slice of commits and returns a markdown-formatted string,
-// including the category header.
+// including the category header, using the built-in template.
 func RenderCommits(
 	commits *[]vcs.TagCommits,
 	groupIntoSections bool,
 	releaseUnreleased bool,
 	unreleasedVersionName string,
-) string {
-	if commits == nil {
-		logrus.Debug("no commits to render")
-		return ""
-	}
-	var output string
-	for _, tagCommits := range *commits {
-		var unreleased bool
-		var versionName string
-		if tagCommits.Name == vcs.UnreleasedVersionName {
-			unreleased = !releaseUnreleased
-			versionName = unreleasedVersionName
-		} else {
-			unreleased = false
-			version := tagCommits.Name
-			if strings.HasPrefix(version, "v") {
-				version = strings.TrimPrefix(version, "v")
-			}
-			versionName = version
-		}
-
-		// write version header
-		if len(output) > 0 {
-			output += "\n\n"
-		}
-		output += "## [" + versionName + "]"
-		if !unreleased {
-			output += " - " + tagCommits.Date.Format("2006-01-02")
-		}
-		output += "\n"
-
-		categorised := convcommits.CategoriseByType(tagCommits.Commits)
-		if groupIntoSections {
-			categorised = groupBySection(categorised)
-		}
-
-		categories := maps.Keys(categorised)
-		sort.Strings(categories)
-
-		for _, category := range categories {
-			output += "### " + category + "\n"
-			items := categorised[category]
-			sort.Strings(items)
-
-			for _, commit := range items {
-				output += "- " + commit + "\n"
-			}
-			output += "\n"
-		}
-		output = strings.TrimSpace(output)
-		logrus.Debugf("grouped %d commits for version %s into %d sections\n", len(tagCommits.Commits), tagCommits.Name, len(maps.Keys(categorised)))
-	}
-	return output
+) (string, error) {
+	return defaultRenderer.Render(commits, groupIntoSections, releaseUnreleased, unreleasedVersionName)
 }
 
 // SplitIntoSections takes a slice of changelog lines and splits it into
-// boilerplate and body sections.
+// boilerplate and body sections. Any Unreleased section is omitted from
+// the body, and returned separately.
 func SplitIntoSections(lines []string) Sections {
-	var boilerplate string
+	return splitDocument(ParseDocument(lines))
+}
 
-	// find the first h2
-	firstH2 := 0
-	skipping := false
+// splitDocument splits a parsed changelog into boilerplate and body
+// sections, omitting any Unreleased section from the body.
+func splitDocument(doc *Document) Sections {
+	unreleased := doc.RemoveUnreleased()
 
-	for i, line := range lines {
-		if strings.HasPrefix(line, "## ") {
-			if strings.Contains(line, "[Unreleased]") {
-				skipping = true
-			} else {
-				firstH2 = i
-				break
-			}
-		} else {
-			if !skipping {
-				boilerplate += line + "\n"
-			}
-		}
+	var boilerplate string
+	for _, line := range doc.Preamble {
+		boilerplate += line + "\n"
 	}
-
-	var body string
-	if firstH2 > 0 {
-		for _, line := range lines[firstH2:] {
-			body += line + "\n"
-		}
+	if strings.TrimSpace(boilerplate) == "" {
+		boilerplate = ""
 	}
+
+	body := (&Document{Releases: doc.Releases, Footer: doc.Footer}).Render()
 	sections := Sections{
 		Boilerplate: boilerplate,
 		Body:        strings.TrimSpace(body),
+		Unreleased:  unreleased,
 	}
 	return sections
 }
 
-// groupBySection maps the commit prefixes to sections.
-func groupBySection(input map[string][]string) map[string][]string {
-	output := make(map[string][]string)
-	for prefix, commits := range input {
-		prefix = mapTypeToSection(prefix)
-
-		existing := output[prefix]
-		commits = append(existing, commits...)
-		output[prefix] = commits
-	}
-	return output
-}
-
 // mapTypeToSection maps a commit prefix to a section.
 func mapTypeToSection(prefix string) string {
 	mapped := false
@@ -179,6 +127,19 @@ func mapTypeToSection(prefix string) string {
 	return prefix
 }
 
+// sectionLess reports whether the section named a sorts before the section
+// named b: breaking changes come first and contributors last, and other
+// sections are sorted by name.
+func sectionLess(a string, b string) bool {
+	if (a == BreakingChangesSectionName) != (b == BreakingChangesSectionName) {
+		return a == BreakingChangesSectionName
+	}
+	if (a == ContributorsSectionName) != (b == ContributorsSectionName) {
+		return b == ContributorsSectionName
+	}
+	return a < b
+}
+
 // GetUpdatedChangelog returns the updated changelog, grouped by version headers.
 func GetUpdatedChangelog(
 	config cfg.SinceConfig,
@@ -193,12 +154,39 @@ func GetUpdatedChangelog(
 	if err != nil {
 		return vcs.ReleaseMetadata{}, "", fmt.Errorf("failed to fetch commit messages from repo: %s: %v", repoPath, err)
 	}
-	if len(*commits) == 0 {
-		return vcs.ReleaseMetadata{}, "", &NoChangesError{
-			StartTag:      afterTag,
-			ExcludedCount: stats.Excluded,
+
+	// fragments and entries written by hand are only added to a new release
+	var fragments []Fragment
+	if beforeTag == "" {
+		if fragments, err = ReadFragments(config, repoPath); err != nil {
+			return vcs.ReleaseMetadata{}, "", err
 		}
 	}
+	noChanges := &NoChangesError{
+		StartTag:      afterTag,
+		ExcludedCount: stats.Excluded,
+	}
+
+	format, err := ResolveFormat(config, changelogFile)
+	if err != nil {
+		return vcs.ReleaseMetadata{}, "", err
+	}
+	lines, err := ReadFile(changelogFile)
+	if err != nil {
+		if len(*commits) == 0 && len(fragments) == 0 {
+			return vcs.ReleaseMetadata{}, "", noChanges
+		}
+		return vcs.ReleaseMetadata{}, "", fmt.Errorf("failed to read changelog file: %s: %v", changelogFile, err)
+	}
+	sections := splitDocument(ParseDocumentAs(lines, format))
+
+	var manual []SectionData
+	if beforeTag == "" {
+		manual = unreleasedEntries(sections.Unreleased)
+	}
+	if len(*commits) == 0 && len(fragments) == 0 && len(manual) == 0 {
+		return vcs.ReleaseMetadata{}, "", noChanges
+	}
 
 	currentVersion, vPrefix, err := semver.GetCurrentVersion(repoPath, orderBy)
 	if err != nil {
@@ -208,29 +196,34 @@ func GetUpdatedChangelog(
 	var nextVersion string
 	var releaseUnreleased bool
 	if beforeTag == "" {
-		// determine next version only based on unreleased commits
-		unreleasedCommits := (*commits)[0].Commits
+		// determine next version only based on unreleased commits, fragments and manual entries
+		var unreleasedCommits []string
+		minimum := minimumBump(fragments, manual)
+		if len(*commits) > 0 {
+			unreleasedCommits = (*commits)[0].Commits
+			minimum = semver.MaxComponent(minimum, semver.BreakingChangeBump((*commits)[0].CommitDetails()))
+		}
 
 		// always disable vPrefix for changelog heading
-		nextVersion = semver.GetNextVersion(currentVersion, false, unreleasedCommits)
+		nextVersion = semver.GetNextVersionWithBump(currentVersion, false, unreleasedCommits, minimum)
 		if nextVersion == "" {
 			return vcs.ReleaseMetadata{}, "", fmt.Errorf("could not determine next version")
 		}
 
 		releaseUnreleased = true
+		ensureUnreleased(commits)
 	} else {
 		nextVersion = vcs.UnreleasedVersionName
 	}
 
-	rendered := RenderCommits(commits, true, releaseUnreleased, nextVersion)
-
-	lines, err := ReadFile(changelogFile)
+	renderer, err := NewRenderer(config, repoPath, format)
 	if err != nil {
-		return vcs.ReleaseMetadata{}, "", fmt.Errorf("failed to read changelog file: %s: %v", changelogFile, err)
+		return vcs.ReleaseMetadata{}, "", err
 	}
-	sections := SplitIntoSections(lines)
+	renderer.Unreleased = append(manual, fragmentEntries(fragments)...)
+	rendered, err := renderer.Render(commits, true, releaseUnreleased, nextVersion)
 	if err != nil {
-		return vcs.ReleaseMetadata{}, "", fmt.Errorf("failed to split changes into sections: %v", err)
+		return vcs.ReleaseMetadata{}, "", err
 	}
 
 	output := sections.Boilerplate + rendered + "\n\n" + sections.Body
@@ -239,12 +232,22 @@ func GetUpdatedChangelog(
 	if err != nil {
 		return vcs.ReleaseMetadata{}, "", fmt.Errorf("failed to get head sha: %v", err)
 	}
+	var tag string
+	if releaseUnreleased {
+		tag = nextVersion
+		if vPrefix {
+			tag = "v" + tag
+		}
+	}
 	metadata = vcs.ReleaseMetadata{
 		OldVersion: currentVersion,
 		NewVersion: nextVersion,
 		RepoPath:   repoPath,
 		Sha:        sha,
 		VPrefix:    vPrefix,
+		Tag:        tag,
+		Notes:      rendered,
+		Fragments:  fragmentPaths(fragments),
 	}
 	return metadata, output, nil
 }
@@ -257,7 +260,11 @@ func InitChangelog(
 	orderBy vcs.TagOrderBy,
 	repoPath string,
 ) (newChangelog string, err error) {
-	err = WriteChangelog(changelogFile, changelogTemplate)
+	format, err := ResolveFormat(config, changelogFile)
+	if err != nil {
+		return "", err
+	}
+	err = WriteChangelog(changelogFile, changelogBoilerplate(format))
 	if err != nil {
 		return "", fmt.Errorf("failed to initialise changelog: %s: %v", changelogFile, err)
 	}
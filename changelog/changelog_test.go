@@ -17,6 +17,7 @@ limitations under the License.
 package changelog
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -116,7 +117,11 @@ func TestRenderCommits(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := RenderCommits(tt.args.commits, tt.args.groupIntoSections, tt.args.releaseUnreleased, vcs.UnreleasedVersionName); got != tt.want {
+			got, err := RenderCommits(tt.args.commits, tt.args.groupIntoSections, tt.args.releaseUnreleased, vcs.UnreleasedVersionName)
+			if err != nil {
+				t.Fatalf("RenderCommits() error = %v", err)
+			}
+			if got != tt.want {
 				t.Errorf("RenderCommits() got = %v, want %v", got, tt.want)
 			}
 		})
@@ -128,9 +133,10 @@ func TestSplitIntoSections(t *testing.T) {
 		lines []string
 	}
 	tests := []struct {
-		name string
-		args args
-		want Sections
+		name           string
+		args           args
+		want           Sections
+		wantUnreleased bool
 	}{
 		{
 			name: "no sections",
@@ -149,6 +155,17 @@ func TestSplitIntoSections(t *testing.T) {
 				Body:        "## [0.1.0]\n### feat\n- feat: foo\n\n### fix\n- fix: bar",
 			},
 		},
+		{
+			name: "skip unreleased and code blocks",
+			args: args{
+				lines: []string{"# Change Log", "", "## [Unreleased]", "- feat: wip", "", "## [0.1.0]", "```", "## [0.0.1]", "```", "", "[0.1.0]: https://example.com", ""},
+			},
+			want: Sections{
+				Boilerplate: "# Change Log\n\n",
+				Body:        "## [0.1.0]\n```\n## [0.0.1]\n```\n\n[0.1.0]: https://example.com",
+			},
+			wantUnreleased: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -159,6 +176,9 @@ func TestSplitIntoSections(t *testing.T) {
 			if !reflect.DeepEqual(got.Body, tt.want.Body) {
 				t.Errorf("SplitIntoSections() Body got = %v, want %v", got, tt.want)
 			}
+			if (got.Unreleased != nil) != tt.wantUnreleased {
+				t.Errorf("SplitIntoSections() Unreleased got = %v, want %v", got.Unreleased, tt.wantUnreleased)
+			}
 		})
 	}
 }
@@ -229,6 +249,13 @@ func TestGetUpdatedChangelog(t *testing.T) {
 				RepoPath:   repoWithTagsAndUnreleasedChanges,
 				Sha:        unreleasedCommitSha.String(),
 				VPrefix:    false,
+				Tag:        "0.2.0",
+				Notes: fmt.Sprintf(`## [0.2.0] - %v
+### Added
+- feat: unreleased change
+
+### Changed
+- docs: adds changelog`, today),
 			},
 			wantUpdatedChangelog: fmt.Sprintf(`# Changelog
 
@@ -261,6 +288,17 @@ and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0
 				RepoPath:   repoWithTagsAndUnreleasedChanges,
 				Sha:        unreleasedCommitSha.String(),
 				VPrefix:    false,
+				Tag:        "0.2.0",
+				Notes: fmt.Sprintf(`## [0.2.0] - %[1]v
+### Added
+- feat: unreleased change
+
+### Changed
+- docs: adds changelog
+
+## [0.1.0] - %[1]v
+### Added
+- feat: second update`, today),
 			},
 			wantUpdatedChangelog: fmt.Sprintf(`# Changelog
 
@@ -305,6 +343,164 @@ and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0
 	}
 }
 
+func TestGetUpdatedChangelog_mergesUnreleasedEntries(t *testing.T) {
+	repoDir := createTestRepo(t)
+	commitChange(t, repoDir, "CHANGELOG.md", `# Changelog
+
+## [Unreleased]
+### Added
+- feat: unreleased change
+- A feature described by hand
+
+### Security
+- Fixed a vulnerability
+`, "fix: a bug", time.Now())
+	commitChange(t, repoDir, "README.md", "unreleased change\r\n", "feat: unreleased change", time.Now())
+
+	metadata, updated, err := GetUpdatedChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, path.Join(repoDir, "CHANGELOG.md"), vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetUpdatedChangelog() error = %v", err)
+	}
+
+	want := fmt.Sprintf(`# Changelog
+
+## [0.2.0] - %v
+### Added
+- A feature described by hand
+- feat: unreleased change
+
+### Fixed
+- fix: a bug
+
+### Security
+- Fixed a vulnerability
+
+`, time.Now().Format("2006-01-02"))
+	if updated != want {
+		t.Errorf("GetUpdatedChangelog() gotUpdatedChangelog = %v, want %v", updated, want)
+	}
+	if !strings.HasSuffix(want, metadata.Notes+"\n\n") {
+		t.Errorf("GetUpdatedChangelog() notes = %v, want merged entries", metadata.Notes)
+	}
+}
+
+func TestGetUpdatedChangelog_onlyUnreleasedEntries(t *testing.T) {
+	repoDir := createTestRepo(t)
+	changelogFile := path.Join(repoDir, "CHANGELOG.md")
+	if err := os.WriteFile(changelogFile, []byte("# Changelog\n\n## [Unreleased]\n### Added\n- A feature described by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, updated, err := GetUpdatedChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, changelogFile, vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetUpdatedChangelog() error = %v", err)
+	}
+	if metadata.NewVersion != "0.2.0" {
+		t.Errorf("GetUpdatedChangelog() version = %v, want 0.2.0", metadata.NewVersion)
+	}
+	want := fmt.Sprintf("## [0.2.0] - %v\n### Added\n- A feature described by hand", time.Now().Format("2006-01-02"))
+	if !strings.Contains(updated, want) {
+		t.Errorf("GetUpdatedChangelog() = %v, want release of the unreleased entries", updated)
+	}
+	if strings.Contains(updated, "[Unreleased]") {
+		t.Errorf("GetUpdatedChangelog() kept the Unreleased section: %v", updated)
+	}
+}
+
+func TestGetUpdatedChangelog_skippedCommits(t *testing.T) {
+	repoDir := createTestRepo(t)
+	changelogFile := path.Join(repoDir, "CHANGELOG.md")
+	commitChange(t, repoDir, "CHANGELOG.md", "# Changelog\n", "feat!: drop the v1 API\n\nChangelog: skip", time.Now())
+
+	_, _, err := GetUpdatedChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, changelogFile, vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	var noChanges *NoChangesError
+	if !errors.As(err, &noChanges) {
+		t.Fatalf("GetUpdatedChangelog() with only skipped commits error = %v, want NoChangesError", err)
+	}
+
+	commitChange(t, repoDir, "README.md", "fixed\n", "fix: a bug", time.Now())
+	metadata, updated, err := GetUpdatedChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, changelogFile, vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetUpdatedChangelog() error = %v", err)
+	}
+	if metadata.NewVersion != "0.1.1" {
+		t.Errorf("GetUpdatedChangelog() version = %v, want 0.1.1, ignoring the skipped breaking change", metadata.NewVersion)
+	}
+	if strings.Contains(updated, "v1 API") {
+		t.Errorf("GetUpdatedChangelog() listed the skipped commit: %v", updated)
+	}
+}
+
+func TestGetUpdatedChangelog_debianFormat(t *testing.T) {
+	repoDir := createTestRepo(t)
+	if err := os.Mkdir(path.Join(repoDir, "debian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	commitChange(t, repoDir, "debian/changelog", `since (0.1.0) unstable; urgency=medium
+
+  * Initial release.
+
+ -- Jane Doe <jane@example.com>  Mon, 04 Mar 2024 12:00:00 +0000
+`, "feat: package for debian", time.Now())
+
+	config := cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Package: cfg.PackageConfig{Maintainer: "Since Bot <bot@example.com>"}}}
+	_, updated, err := GetUpdatedChangelog(config, vcs.CommitConfig{}, path.Join(repoDir, "debian", "changelog"), vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetUpdatedChangelog() error = %v", err)
+	}
+
+	doc := ParseDocumentAs(strings.Split(updated, "\n"), FormatDebian)
+	if len(doc.Releases) != 2 || doc.Releases[0].Version != "0.2.0" || doc.Releases[1].Version != "0.1.0" {
+		t.Fatalf("GetUpdatedChangelog() releases = %+v, want 0.2.0 and 0.1.0", doc.Releases)
+	}
+	wantHeading := fmt.Sprintf("%s (0.2.0) unstable; urgency=medium", path.Base(repoDir))
+	if doc.Releases[0].Heading != wantHeading {
+		t.Errorf("GetUpdatedChangelog() heading = %q, want %q", doc.Releases[0].Heading, wantHeading)
+	}
+	if !strings.Contains(updated, "  [ Added ]\n  * feat: package for debian\n\n -- Since Bot <bot@example.com>  ") {
+		t.Errorf("GetUpdatedChangelog() missing new entry and trailer, got:\n%s", updated)
+	}
+	if !strings.HasSuffix(updated, "\n\nsince (0.1.0) unstable; urgency=medium\n\n  * Initial release.\n\n -- Jane Doe <jane@example.com>  Mon, 04 Mar 2024 12:00:00 +0000") {
+		t.Errorf("GetUpdatedChangelog() did not keep the previous release, got:\n%s", updated)
+	}
+}
+
+func TestGetUpdatedChangelog_specFile(t *testing.T) {
+	repoDir := createTestRepo(t)
+	commitChange(t, repoDir, "since.spec", `Name: since
+Version: 0.1.0
+
+%changelog
+* Mon Mar 04 2024 Jane Doe <jane@example.com> - 0.1.0
+- Initial release
+
+%files
+/usr/bin/since
+`, "feat: package for fedora", time.Now())
+
+	config := cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Package: cfg.PackageConfig{Maintainer: "Since Bot <bot@example.com>"}}}
+	_, updated, err := GetUpdatedChangelog(config, vcs.CommitConfig{}, path.Join(repoDir, "since.spec"), vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetUpdatedChangelog() error = %v", err)
+	}
+
+	want := fmt.Sprintf(`Name: since
+Version: 0.1.0
+
+%%changelog
+* %s Since Bot <bot@example.com> - 0.2.0
+- feat: package for fedora
+
+* Mon Mar 04 2024 Jane Doe <jane@example.com> - 0.1.0
+- Initial release
+
+%%files
+/usr/bin/since`, time.Now().Format("Mon Jan 02 2006"))
+	if updated != want {
+		t.Errorf("GetUpdatedChangelog() got = %q, want %q", updated, want)
+	}
+}
+
 func TestInitChangelog(t *testing.T) {
 	repoDir := createTestRepo(t)
 	// add an unreleased commit so there are changes to render
@@ -0,0 +1,120 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/convcommits"
+	"github.com/release-tools/since/vcs"
+	"golang.org/x/exp/slices"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// coAuthorTrailer is the trailer crediting additional authors of a commit.
+const coAuthorTrailer = "Co-authored-by"
+
+// ContributorData is a contributor to a release.
+type ContributorData struct {
+	Name  string
+	Email string
+}
+
+// ContributorOptions configures the list of contributors added to each release.
+type ContributorOptions struct {
+	// Mailmap maps author names and emails to their canonical form.
+	Mailmap *vcs.Mailmap
+
+	// Exclude holds patterns matched against the name and email of each
+	// contributor. Matching contributors are left out.
+	Exclude []*regexp.Regexp
+}
+
+// newContributorOptions returns the contributor options for the config,
+// loading the mailmap of the repository, or nil if contributors are
+// not enabled.
+func newContributorOptions(config cfg.ContributorsConfig, repoPath string) (*ContributorOptions, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	mailmap, err := vcs.LoadMailmap(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	opts := &ContributorOptions{Mailmap: mailmap}
+	for _, exclude := range config.Exclude {
+		pattern, err := regexp.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contributor exclude pattern %q: %w", exclude, err)
+		}
+		opts.Exclude = append(opts.Exclude, pattern)
+	}
+	return opts, nil
+}
+
+// list returns the authors and co-authors of the commits, de-duplicated
+// by email after applying the mailmap, and sorted by name.
+func (o *ContributorOptions) list(details []vcs.CommitDetail) []ContributorData {
+	var contributors []ContributorData
+	seen := make(map[string]bool)
+	add := func(name string, email string) {
+		if o.Mailmap != nil {
+			name, email = o.Mailmap.Resolve(name, email)
+		}
+		if name == "" || o.excluded(name, email) {
+			return
+		}
+		key := strings.ToLower(email)
+		if key == "" {
+			key = strings.ToLower(name)
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		contributors = append(contributors, ContributorData{Name: name, Email: email})
+	}
+
+	for _, detail := range details {
+		add(detail.Author, detail.AuthorEmail)
+		for _, coAuthor := range convcommits.GetTrailerValues(detail.Body, coAuthorTrailer) {
+			if address, err := mail.ParseAddress(coAuthor); err == nil {
+				add(address.Name, address.Address)
+			} else {
+				add(coAuthor, "")
+			}
+		}
+	}
+
+	slices.SortStableFunc(contributors, func(a, b ContributorData) bool {
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	return contributors
+}
+
+// excluded returns true if the name or email of the contributor matches
+// any of the exclude patterns.
+func (o *ContributorOptions) excluded(name string, email string) bool {
+	for _, exclude := range o.Exclude {
+		if exclude.MatchString(name) || (email != "" && exclude.MatchString(email)) {
+			return true
+		}
+	}
+	return false
+}
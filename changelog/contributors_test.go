@@ -0,0 +1,131 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestContributorOptions_list(t *testing.T) {
+	repoDir := t.TempDir()
+	mailmap := "Jane Doe <jane@example.com> <jane@laptop.local>\n"
+	if err := os.WriteFile(filepath.Join(repoDir, vcs.MailmapFile), []byte(mailmap), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := newContributorOptions(cfg.ContributorsConfig{Enabled: true, Exclude: []string{`\[bot\]$`}}, repoDir)
+	if err != nil {
+		t.Fatalf("newContributorOptions() error = %v", err)
+	}
+
+	details := []vcs.CommitDetail{
+		{Message: "feat: add pagination", Author: "jane", AuthorEmail: "jane@laptop.local", Body: "Co-authored-by: Joe Bloggs <joe@example.com>"},
+		{Message: "fix: correct layout", Author: "Jane Doe", AuthorEmail: "jane@example.com"},
+		{Message: "chore: bump deps", Author: "dependabot[bot]", AuthorEmail: "49699333+dependabot[bot]@users.noreply.github.com"},
+		{Message: "docs: fix typo", Author: "Alex Smith", AuthorEmail: "alex@example.com", Body: "Co-authored-by: joe bloggs <JOE@example.com>"},
+	}
+	got := opts.list(details)
+	want := []ContributorData{
+		{Name: "Alex Smith", Email: "alex@example.com"},
+		{Name: "Jane Doe", Email: "jane@example.com"},
+		{Name: "Joe Bloggs", Email: "joe@example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("list() = %v, want %v", got, want)
+	}
+}
+
+func Test_newContributorOptions(t *testing.T) {
+	if opts, err := newContributorOptions(cfg.ContributorsConfig{}, t.TempDir()); err != nil || opts != nil {
+		t.Errorf("newContributorOptions() = %v, %v, want nil when disabled", opts, err)
+	}
+	if _, err := newContributorOptions(cfg.ContributorsConfig{Enabled: true, Exclude: []string{"["}}, t.TempDir()); err == nil {
+		t.Error("newContributorOptions() expected error for an invalid exclude pattern")
+	}
+}
+
+func TestRenderer_Render_contributors(t *testing.T) {
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: "v1.0.0", Date: time.Date(2023, 8, 28, 0, 0, 0, 0, time.UTC)},
+			Commits: []string{"feat: add pagination", "fix: correct layout"},
+			Details: []vcs.CommitDetail{
+				{Message: "feat: add pagination", Author: "Joe Bloggs", AuthorEmail: "joe@example.com"},
+				{Message: "fix: correct layout", Author: "Jane Doe", AuthorEmail: "jane@example.com"},
+			},
+		},
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatMarkdown,
+			want: `## [1.0.0] - 2023-08-28
+### Added
+- feat: add pagination
+
+### Fixed
+- fix: correct layout
+
+### Contributors
+- Jane Doe
+- Joe Bloggs`,
+		},
+		{
+			format: FormatDebian,
+			want: `pkg (1.0.0) unstable; urgency=medium
+
+  [ Added ]
+  * feat: add pagination
+
+  [ Fixed ]
+  * fix: correct layout
+
+  [ Contributors ]
+  * Jane Doe
+  * Joe Bloggs
+
+ -- Joe Bloggs <joe@example.com>  Mon, 28 Aug 2023 00:00:00 +0000`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := cfg.SinceConfig{Changelog: cfg.ChangelogConfig{
+				Package:      cfg.PackageConfig{Name: "pkg"},
+				Contributors: cfg.ContributorsConfig{Enabled: true},
+			}}
+			renderer, err := NewRenderer(config, t.TempDir(), tt.format)
+			if err != nil {
+				t.Fatalf("NewRenderer() error = %v", err)
+			}
+			got, err := renderer.Render(&commits, true, false, vcs.UnreleasedVersionName)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
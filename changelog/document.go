@@ -0,0 +1,363 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/vcs"
+	"regexp"
+	"strings"
+)
+
+// Document is a parsed changelog file.
+//
+// Every line of the source file belongs to exactly one part of the
+// document, and each part keeps its original lines, so rendering an
+// unmodified document reproduces the source byte for byte.
+type Document struct {
+	// Preamble holds the lines before the first release heading,
+	// such as the title and introduction.
+	Preamble []string
+
+	// Releases holds the release sections, including any Unreleased
+	// section, in the order they appear in the file.
+	Releases []*Release
+
+	// Footer holds the trailing block of link reference definitions,
+	// and any blank lines between them, or the sections of an RPM spec
+	// file after its changelog.
+	Footer []string
+
+	// Links holds the link reference definitions parsed from the footer.
+	Links []LinkReference
+}
+
+// Release is a release section, introduced by a level 2 heading,
+// such as '## [1.0.0] - 2024-01-01'.
+type Release struct {
+	// Heading is the original heading line.
+	Heading string
+
+	// Version is the version from the heading, such as '1.0.0', or
+	// vcs.UnreleasedVersionName for the Unreleased section.
+	Version string
+
+	// Date is the release date from the heading, if present.
+	Date string
+
+	// Yanked is true if the heading is marked '[YANKED]'.
+	Yanked bool
+
+	// Intro holds the lines between the heading and the first section.
+	Intro []string
+
+	// Sections holds the change type sections of the release.
+	Sections []*Section
+
+	// Line is the 1-based line number of the heading in the source.
+	Line int
+}
+
+// Section is a group of changes within a release, introduced by a
+// level 3 heading, such as '### Added'.
+type Section struct {
+	// Heading is the original heading line. It is empty for the implicit
+	// section holding the entries of formats without section headings.
+	Heading string
+
+	// Name is the section name from the heading, such as 'Added'.
+	Name string
+
+	// Lines holds the original lines of the section body.
+	Lines []string
+
+	// Entries holds the list items parsed from the section body.
+	Entries []*Entry
+
+	// Line is the 1-based line number of the heading in the source.
+	Line int
+}
+
+// Entry is a single list item within a section.
+type Entry struct {
+	// Text is the text of the list item, without the bullet.
+	Text string
+
+	// Details holds any indented continuation lines of the item,
+	// with surrounding whitespace removed.
+	Details []string
+}
+
+// LinkReference is a link reference definition, such as
+// '[1.0.0]: https://example.com/compare/0.9.0...1.0.0'.
+type LinkReference struct {
+	Label string
+	URL   string
+
+	// Line is the 1-based line number of the definition in the source.
+	Line int
+}
+
+var (
+	versionHeadingRegex = regexp.MustCompile(`^(?:\[([^\]]+)\](?:\([^)]*\))?|(\S+))(?:\s+-\s+(.+?))?(\s+\[YANKED\])?\s*$`)
+	linkReferenceRegex  = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*(\S+)(?:\s+.*)?$`)
+	entryRegex          = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+)
+
+// ParseDocument parses the lines of a changelog file into a Document.
+// Headings inside fenced code blocks are ignored.
+func ParseDocument(lines []string) *Document {
+	doc := &Document{}
+	fenced := findFencedLines(lines)
+
+	// the footer is the trailing run of link reference definitions and blank lines
+	footerStart := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimRight(lines[i], "\r")
+		if fenced[i] || (strings.TrimSpace(line) != "" && !linkReferenceRegex.MatchString(line)) {
+			break
+		}
+		footerStart = i
+	}
+	var links []LinkReference
+	for i := footerStart; i < len(lines); i++ {
+		if m := linkReferenceRegex.FindStringSubmatch(strings.TrimRight(lines[i], "\r")); m != nil {
+			links = append(links, LinkReference{Label: m[1], URL: m[2], Line: i + 1})
+		}
+	}
+	if len(links) == 0 {
+		// trailing blank lines without links belong to the last part of the document
+		footerStart = len(lines)
+	} else {
+		doc.Footer = lines[footerStart:]
+		doc.Links = links
+	}
+
+	var release *Release
+	var section *Section
+	for i, line := range lines[:footerStart] {
+		trimmed := strings.TrimRight(line, "\r")
+		switch {
+		case !fenced[i] && strings.HasPrefix(trimmed, "## "):
+			release = parseReleaseHeading(line)
+			release.Line = i + 1
+			section = nil
+			doc.Releases = append(doc.Releases, release)
+
+		case !fenced[i] && release != nil && strings.HasPrefix(trimmed, "### "):
+			section = &Section{
+				Heading: line,
+				Name:    strings.TrimSpace(strings.TrimPrefix(trimmed, "###")),
+				Line:    i + 1,
+			}
+			release.Sections = append(release.Sections, section)
+
+		case section != nil:
+			section.Lines = append(section.Lines, line)
+			if !fenced[i] {
+				section.addLine(trimmed)
+			}
+
+		case release != nil:
+			release.Intro = append(release.Intro, line)
+
+		default:
+			doc.Preamble = append(doc.Preamble, line)
+		}
+	}
+	return doc
+}
+
+// addLine updates the entries of the section with a body line.
+func (s *Section) addLine(line string) {
+	if m := entryRegex.FindStringSubmatch(line); m != nil {
+		s.Entries = append(s.Entries, &Entry{Text: strings.TrimSpace(m[1])})
+		return
+	}
+	if len(s.Entries) > 0 && strings.TrimSpace(line) != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+		last := s.Entries[len(s.Entries)-1]
+		last.Details = append(last.Details, strings.TrimSpace(line))
+	}
+}
+
+// render regenerates the heading and lines of the section from its
+// name and entries. A section without a name has no heading.
+func (s *Section) render() {
+	s.Heading = ""
+	if s.Name != "" {
+		s.Heading = "### " + s.Name
+	}
+	s.Lines = nil
+	for _, entry := range s.Entries {
+		s.Lines = append(s.Lines, entry.lines()...)
+	}
+}
+
+// lines returns the markdown list item for the entry.
+func (e *Entry) lines() []string {
+	lines := []string{"- " + e.Text}
+	for _, detail := range e.Details {
+		lines = append(lines, "  "+detail)
+	}
+	return lines
+}
+
+// parseReleaseHeading parses a level 2 heading into a Release.
+// Headings that do not follow the expected format use the heading
+// text as the version.
+func parseReleaseHeading(heading string) *Release {
+	text := strings.TrimPrefix(strings.TrimRight(heading, "\r"), "##")
+	return parseVersionHeading(heading, text)
+}
+
+// parseVersionHeading parses the text of a release heading, such as
+// '[1.0.0] - 2024-01-01', into a Release with the given heading line.
+func parseVersionHeading(heading string, text string) *Release {
+	release := &Release{Heading: heading}
+	text = strings.TrimSpace(text)
+	if m := versionHeadingRegex.FindStringSubmatch(text); m != nil {
+		release.Version = m[1] + m[2]
+		release.Date = m[3]
+		release.Yanked = m[4] != ""
+	} else {
+		release.Version = text
+	}
+	if strings.EqualFold(release.Version, vcs.UnreleasedVersionName) {
+		release.Version = vcs.UnreleasedVersionName
+	}
+	return release
+}
+
+// findFencedLines returns whether each line is part of a fenced code
+// block, including the opening and closing fences.
+func findFencedLines(lines []string) []bool {
+	fenced := make([]bool, len(lines))
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(strings.TrimRight(line, "\r"), " ")
+		if len(line)-len(strings.TrimLeft(line, " ")) > 3 {
+			trimmed = ""
+		}
+		if fence == "" {
+			if marker := fenceMarker(trimmed); marker != "" {
+				fence = marker
+				fenced[i] = true
+			}
+			continue
+		}
+		fenced[i] = true
+		if marker := fenceMarker(trimmed); strings.HasPrefix(marker, fence) && strings.TrimSpace(trimmed[len(marker):]) == "" {
+			fence = ""
+		}
+	}
+	return fenced
+}
+
+// fenceMarker returns the run of backticks or tildes that opens a
+// code fence at the start of the line, or an empty string.
+func fenceMarker(line string) string {
+	for _, c := range []string{"`", "~"} {
+		if strings.HasPrefix(line, c+c+c) {
+			n := len(line) - len(strings.TrimLeft(line, c))
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// IsUnreleased returns true if this is the Unreleased section.
+func (r *Release) IsUnreleased() bool {
+	return r.Version == vcs.UnreleasedVersionName || strings.HasSuffix(r.Version, UnreleasedVersionSuffix)
+}
+
+// Lines returns the original lines of the release, including the heading.
+func (r *Release) Lines() []string {
+	lines := append([]string{r.Heading}, r.Intro...)
+	for _, section := range r.Sections {
+		if section.Heading != "" {
+			lines = append(lines, section.Heading)
+		}
+		lines = append(lines, section.Lines...)
+	}
+	return lines
+}
+
+// Lines returns the lines of the document.
+func (d *Document) Lines() []string {
+	var lines []string
+	lines = append(lines, d.Preamble...)
+	for _, release := range d.Releases {
+		lines = append(lines, release.Lines()...)
+	}
+	lines = append(lines, d.Footer...)
+	return lines
+}
+
+// Render returns the document as a string. An unmodified document
+// renders identically to the file it was parsed from.
+func (d *Document) Render() string {
+	return strings.Join(d.Lines(), "\n")
+}
+
+// FindRelease returns the release with the given version, or nil if
+// there is none. Versions are matched case-insensitively, so that an
+// '[unreleased]' link matches an 'Unreleased' heading, and a leading 'v'
+// is ignored.
+func (d *Document) FindRelease(version string) *Release {
+	for _, release := range d.Releases {
+		if strings.EqualFold(strings.TrimPrefix(release.Version, "v"), strings.TrimPrefix(version, "v")) {
+			return release
+		}
+	}
+	return nil
+}
+
+// FindLink returns the link reference definition whose label matches
+// the given version, or nil if there is none. Labels are matched
+// case-insensitively, and a leading 'v' is ignored.
+func (d *Document) FindLink(version string) *LinkReference {
+	for i, link := range d.Links {
+		if strings.EqualFold(strings.TrimPrefix(link.Label, "v"), strings.TrimPrefix(version, "v")) {
+			return &d.Links[i]
+		}
+	}
+	return nil
+}
+
+// RemoveUnreleased removes any Unreleased section from the document,
+// returning it, or nil if there was none.
+func (d *Document) RemoveUnreleased() *Release {
+	var removed *Release
+	var releases []*Release
+	for _, release := range d.Releases {
+		if release.IsUnreleased() && removed == nil {
+			removed = release
+			continue
+		}
+		releases = append(releases, release)
+	}
+	d.Releases = releases
+	return removed
+}
+
+// trimTrailingBlankLines returns the lines without any trailing blank lines.
+func trimTrailingBlankLines(lines []string) []string {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return lines[:end]
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/vcs"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const structuredChangelog = "# Changelog\n" +
+	"\n" +
+	"Intro text.\n" +
+	"\n" +
+	"## [Unreleased]\n" +
+	"### Added\n" +
+	"- feat: pending change\n" +
+	"\n" +
+	"## [1.1.0] - 2024-02-01 [YANKED]\n" +
+	"Release summary.\n" +
+	"\n" +
+	"### Fixed\n" +
+	"- fix: a bug\n" +
+	"  with more detail\n" +
+	"- fix: example\n" +
+	"\n" +
+	"```markdown\n" +
+	"## not a heading\n" +
+	"### nor is this\n" +
+	"```\n" +
+	"\n" +
+	"## 1.0.0 - 2024-01-01\n" +
+	"### Added\n" +
+	"* feat: first release\n" +
+	"\n" +
+	"[Unreleased]: https://example.com/compare/1.1.0...HEAD\n" +
+	"[1.1.0]: https://example.com/compare/1.0.0...1.1.0\n"
+
+func TestParseDocument(t *testing.T) {
+	doc := ParseDocument(strings.Split(structuredChangelog, "\n"))
+
+	if want := []string{"# Changelog", "", "Intro text.", ""}; !reflect.DeepEqual(doc.Preamble, want) {
+		t.Errorf("ParseDocument() preamble = %q, want %q", doc.Preamble, want)
+	}
+	if len(doc.Releases) != 3 {
+		t.Fatalf("ParseDocument() got %d releases, want 3", len(doc.Releases))
+	}
+
+	unreleased := doc.Releases[0]
+	if !unreleased.IsUnreleased() || unreleased.Date != "" || unreleased.Line != 5 {
+		t.Errorf("ParseDocument() unreleased = %+v", unreleased)
+	}
+
+	yanked := doc.Releases[1]
+	if yanked.Version != "1.1.0" || yanked.Date != "2024-02-01" || !yanked.Yanked {
+		t.Errorf("ParseDocument() yanked release = %+v", yanked)
+	}
+	if want := []string{"Release summary.", ""}; !reflect.DeepEqual(yanked.Intro, want) {
+		t.Errorf("ParseDocument() intro = %q, want %q", yanked.Intro, want)
+	}
+	if len(yanked.Sections) != 1 || yanked.Sections[0].Name != "Fixed" {
+		t.Fatalf("ParseDocument() sections = %+v, want a single Fixed section", yanked.Sections)
+	}
+	entries := yanked.Sections[0].Entries
+	if len(entries) != 2 {
+		t.Fatalf("ParseDocument() got %d entries, want 2", len(entries))
+	}
+	if entries[0].Text != "fix: a bug" || !reflect.DeepEqual(entries[0].Details, []string{"with more detail"}) {
+		t.Errorf("ParseDocument() first entry = %+v", entries[0])
+	}
+
+	first := doc.Releases[2]
+	if first.Version != "1.0.0" || first.Date != "2024-01-01" || first.Yanked {
+		t.Errorf("ParseDocument() unbracketed release = %+v", first)
+	}
+	if len(first.Sections) != 1 || first.Sections[0].Entries[0].Text != "feat: first release" {
+		t.Errorf("ParseDocument() unbracketed release sections = %+v", first.Sections)
+	}
+
+	wantLinks := []LinkReference{
+		{Label: "Unreleased", URL: "https://example.com/compare/1.1.0...HEAD", Line: 26},
+		{Label: "1.1.0", URL: "https://example.com/compare/1.0.0...1.1.0", Line: 27},
+	}
+	if !reflect.DeepEqual(doc.Links, wantLinks) {
+		t.Errorf("ParseDocument() links = %+v, want %+v", doc.Links, wantLinks)
+	}
+}
+
+func TestDocument_Render_roundTrip(t *testing.T) {
+	projectChangelog, err := os.ReadFile("../CHANGELOG.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "structured", content: structuredChangelog},
+		{name: "no trailing newline", content: strings.TrimSuffix(structuredChangelog, "\n")},
+		{name: "crlf line endings", content: strings.ReplaceAll(structuredChangelog, "\n", "\r\n")},
+		{name: "only links", content: "# Changelog\n\n[1.0.0]: https://example.com\n\n"},
+		{name: "unclosed fence", content: "# Changelog\n\n## [1.0.0]\n```\n## [0.9.0]\n"},
+		{name: "project changelog", content: string(projectChangelog)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDocument(strings.Split(tt.content, "\n")).Render()
+			if got != tt.content {
+				t.Errorf("Render() = %q, want %q", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestDocument_RemoveUnreleased(t *testing.T) {
+	doc := ParseDocument(strings.Split(structuredChangelog, "\n"))
+
+	removed := doc.RemoveUnreleased()
+	if removed == nil || !removed.IsUnreleased() {
+		t.Fatalf("RemoveUnreleased() = %+v, want Unreleased section", removed)
+	}
+	if len(doc.Releases) != 2 || doc.FindRelease(vcs.UnreleasedVersionName) != nil {
+		t.Errorf("RemoveUnreleased() left releases %+v", doc.Releases)
+	}
+	if doc.RemoveUnreleased() != nil {
+		t.Error("RemoveUnreleased() removed a second section")
+	}
+}
+
+func TestDocument_FindRelease(t *testing.T) {
+	doc := ParseDocument(strings.Split(structuredChangelog, "\n"))
+	if got := doc.FindRelease("v1.0.0"); got == nil || got.Version != "1.0.0" {
+		t.Errorf("FindRelease() = %+v, want 1.0.0", got)
+	}
+	if got := doc.FindRelease("unreleased"); got == nil || got.Version != "Unreleased" {
+		t.Errorf("FindRelease() = %+v, want Unreleased", got)
+	}
+	if got := doc.FindRelease("2.0.0"); got != nil {
+		t.Errorf("FindRelease() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/cfg"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// trailingPunctuation is removed from the end of entries when the
+// trimPunctuation option is set.
+const trailingPunctuation = ".,;:!"
+
+var (
+	pullRequestSuffixRegex = regexp.MustCompile(`\s*\([#!]\d+\)\s*$`)
+	scopeRegex             = regexp.MustCompile(`\([^)]*\)`)
+)
+
+// normaliseEntry returns the text of the changelog entry for a commit,
+// applying the entry options. With no options set, this is the message.
+func normaliseEntry(commit CommitData, opts cfg.EntryConfig, format Format) string {
+	if opts == (cfg.EntryConfig{}) {
+		return commit.Message
+	}
+
+	description := commit.Description
+	if opts.TrimPullRequest {
+		description = pullRequestSuffixRegex.ReplaceAllString(description, "")
+	}
+	if opts.TrimPunctuation {
+		description = strings.TrimSpace(strings.TrimRight(description, trailingPunctuation))
+	}
+	if opts.Capitalise {
+		description = capitalise(description)
+	}
+
+	var prefix string
+	if commit.Type != "" && !opts.StripType {
+		typePrefix := strings.TrimSpace(commit.Message[:strings.Index(commit.Message, ":")])
+		if opts.HighlightScope {
+			typePrefix = scopeRegex.ReplaceAllString(typePrefix, "")
+		}
+		prefix = typePrefix + ": "
+	}
+	if opts.HighlightScope && commit.Scope != "" {
+		prefix += boldText(commit.Scope+":", format) + " "
+	}
+	return prefix + description
+}
+
+// capitalise returns the text with its first letter in upper case.
+func capitalise(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToUpper(r)) + text[size:]
+}
+
+// boldText returns the text marked up as bold in the changelog format.
+// Formats without inline markup return the text unchanged.
+func boldText(text string, format Format) string {
+	switch format {
+	case FormatMarkdown, FormatRST:
+		return "**" + text + "**"
+	case FormatAsciiDoc:
+		return "*" + text + "*"
+	default:
+		return text
+	}
+}
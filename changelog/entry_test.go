@@ -0,0 +1,136 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"testing"
+)
+
+func Test_normaliseEntry(t *testing.T) {
+	all := cfg.EntryConfig{StripType: true, Capitalise: true, HighlightScope: true, TrimPunctuation: true, TrimPullRequest: true}
+	tests := []struct {
+		name    string
+		message string
+		opts    cfg.EntryConfig
+		format  Format
+		want    string
+	}{
+		{
+			name:    "no options",
+			message: "feat(api): add pagination (#12).",
+			want:    "feat(api): add pagination (#12).",
+		},
+		{
+			name:    "strip type",
+			message: "feat(api): add pagination",
+			opts:    cfg.EntryConfig{StripType: true},
+			want:    "add pagination",
+		},
+		{
+			name:    "capitalise",
+			message: "fix: correct layout",
+			opts:    cfg.EntryConfig{Capitalise: true},
+			want:    "fix: Correct layout",
+		},
+		{
+			name:    "highlight scope",
+			message: "feat(api)!: remove v1 endpoints",
+			opts:    cfg.EntryConfig{HighlightScope: true},
+			format:  FormatMarkdown,
+			want:    "feat!: **api:** remove v1 endpoints",
+		},
+		{
+			name:    "trim punctuation and pull request",
+			message: "fix: correct layout. (#123)",
+			opts:    cfg.EntryConfig{TrimPunctuation: true, TrimPullRequest: true},
+			want:    "fix: correct layout",
+		},
+		{
+			name:    "all options",
+			message: "feat(api): add pagination (#12)",
+			opts:    all,
+			format:  FormatMarkdown,
+			want:    "**api:** Add pagination",
+		},
+		{
+			name:    "all options without scope",
+			message: "fix: handle empty input!",
+			opts:    all,
+			format:  FormatMarkdown,
+			want:    "Handle empty input",
+		},
+		{
+			name:    "all options without type",
+			message: "update dependencies.",
+			opts:    all,
+			format:  FormatMarkdown,
+			want:    "Update dependencies",
+		},
+		{
+			name:    "asciidoc scope",
+			message: "feat(api): add pagination",
+			opts:    all,
+			format:  FormatAsciiDoc,
+			want:    "*api:* Add pagination",
+		},
+		{
+			name:    "debian scope",
+			message: "feat(api): add pagination",
+			opts:    all,
+			format:  FormatDebian,
+			want:    "api: Add pagination",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit := buildCommitData(vcs.CommitDetail{Message: tt.message})
+			if got := normaliseEntry(commit, tt.opts, tt.format); got != tt.want {
+				t.Errorf("normaliseEntry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderer_Render_entryOptions(t *testing.T) {
+	renderer := &Renderer{
+		Format:   FormatMarkdown,
+		Template: defaultRenderer.Template,
+		Entries:  cfg.EntryConfig{StripType: true, Capitalise: true, HighlightScope: true},
+	}
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: vcs.UnreleasedVersionName},
+			Commits: []string{"feat(api): add pagination", "fix: correct layout"},
+		},
+	}
+
+	got, err := renderer.Render(&commits, true, false, vcs.UnreleasedVersionName)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `## [Unreleased]
+### Added
+- **api:** Add pagination
+
+### Fixed
+- Correct layout`
+	if got != want {
+		t.Errorf("Render() got = %v, want %v", got, want)
+	}
+}
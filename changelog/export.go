@@ -0,0 +1,134 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// ExportFormat is the format of an exported changelog.
+type ExportFormat string
+
+const (
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatYAML ExportFormat = "yaml"
+)
+
+// ExportedChangelog is the data model of a changelog for export.
+type ExportedChangelog struct {
+	Releases []ExportedRelease `json:"releases" yaml:"releases"`
+	Links    []ExportedLink    `json:"links,omitempty" yaml:"links,omitempty"`
+}
+
+// ExportedRelease is a release section of an exported changelog.
+type ExportedRelease struct {
+	Version    string            `json:"version" yaml:"version"`
+	Date       string            `json:"date,omitempty" yaml:"date,omitempty"`
+	Unreleased bool              `json:"unreleased,omitempty" yaml:"unreleased,omitempty"`
+	Yanked     bool              `json:"yanked,omitempty" yaml:"yanked,omitempty"`
+	Link       string            `json:"link,omitempty" yaml:"link,omitempty"`
+	Sections   []ExportedSection `json:"sections" yaml:"sections"`
+}
+
+// ExportedSection is a section of an exported release. Entries that are
+// not under a section heading are exported as a section with an empty name.
+type ExportedSection struct {
+	Name    string          `json:"name" yaml:"name"`
+	Entries []ExportedEntry `json:"entries" yaml:"entries"`
+}
+
+// ExportedEntry is an entry of an exported section, with any indented
+// lines that follow it as its details.
+type ExportedEntry struct {
+	Text    string   `json:"text" yaml:"text"`
+	Details []string `json:"details,omitempty" yaml:"details,omitempty"`
+}
+
+// ExportedLink is a link reference definition of an exported changelog.
+type ExportedLink struct {
+	Label string `json:"label" yaml:"label"`
+	URL   string `json:"url" yaml:"url"`
+}
+
+// ExportReleases converts the given releases of the document to the export model.
+// Each release is linked to the link reference definition with a matching label,
+// and only the link reference definitions of the given releases are exported.
+func ExportReleases(doc *Document, releases []*Release) ExportedChangelog {
+	exported := ExportedChangelog{Releases: []ExportedRelease{}}
+	linked := make(map[*LinkReference]bool)
+	for _, release := range releases {
+		r := ExportedRelease{
+			Version:    release.Version,
+			Date:       release.Date,
+			Unreleased: release.IsUnreleased(),
+			Yanked:     release.Yanked,
+			Sections:   []ExportedSection{},
+		}
+		if link := doc.FindLink(release.Version); link != nil {
+			r.Link = link.URL
+			linked[link] = true
+		}
+		sections := release.Sections
+		if intro := introSection(release); intro != nil && len(intro.Entries) > 0 {
+			sections = append([]*Section{intro}, sections...)
+		}
+		for _, section := range sections {
+			s := ExportedSection{Name: section.Name, Entries: []ExportedEntry{}}
+			for _, entry := range section.Entries {
+				s.Entries = append(s.Entries, ExportedEntry{Text: entry.Text, Details: entry.Details})
+			}
+			r.Sections = append(r.Sections, s)
+		}
+		exported.Releases = append(exported.Releases, r)
+	}
+	for i, link := range doc.Links {
+		if !linked[&doc.Links[i]] {
+			continue
+		}
+		exported.Links = append(exported.Links, ExportedLink{Label: link.Label, URL: link.URL})
+	}
+	return exported
+}
+
+// Marshal returns the exported changelog in the given format.
+func (e ExportedChangelog) Marshal(format ExportFormat) (string, error) {
+	switch format {
+	case ExportFormatJSON:
+		out, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal changelog to JSON: %w", err)
+		}
+		return string(out), nil
+
+	case ExportFormatYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(e); err != nil {
+			return "", fmt.Errorf("failed to marshal changelog to YAML: %w", err)
+		}
+		_ = encoder.Close()
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
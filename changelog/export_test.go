@@ -0,0 +1,110 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportReleases_entriesWithoutSections(t *testing.T) {
+	doc := ParseDocument(strings.Split(`## [1.1.0]
+- fix: b
+  details of b
+
+### Added
+- feat: a
+
+## [1.0.0]
+- Initial release
+`, "\n"))
+
+	exported := ExportReleases(doc, doc.Releases)
+	want := []ExportedRelease{
+		{
+			Version: "1.1.0",
+			Sections: []ExportedSection{
+				{Name: "", Entries: []ExportedEntry{{Text: "fix: b", Details: []string{"details of b"}}}},
+				{Name: "Added", Entries: []ExportedEntry{{Text: "feat: a"}}},
+			},
+		},
+		{
+			Version: "1.0.0",
+			Sections: []ExportedSection{
+				{Name: "", Entries: []ExportedEntry{{Text: "Initial release"}}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(exported.Releases, want) {
+		t.Errorf("ExportReleases() = %+v, want %+v", exported.Releases, want)
+	}
+}
+
+func TestExportReleases_links(t *testing.T) {
+	doc := ParseDocument(strings.Split(`## [Unreleased]
+
+## [1.1.0]
+- fix: b
+
+## [1.0.0]
+- Initial release
+
+[Unreleased]: https://example.com/compare/1.1.0...HEAD
+[1.1.0]: https://example.com/compare/1.0.0...1.1.0
+[1.0.0]: https://example.com/releases/1.0.0
+`, "\n"))
+
+	tests := []struct {
+		name     string
+		releases []*Release
+		want     []ExportedLink
+	}{
+		{
+			name:     "all releases",
+			releases: doc.Releases,
+			want: []ExportedLink{
+				{Label: "Unreleased", URL: "https://example.com/compare/1.1.0...HEAD"},
+				{Label: "1.1.0", URL: "https://example.com/compare/1.0.0...1.1.0"},
+				{Label: "1.0.0", URL: "https://example.com/releases/1.0.0"},
+			},
+		},
+		{
+			name:     "single release",
+			releases: []*Release{doc.FindRelease("1.1.0")},
+			want: []ExportedLink{
+				{Label: "1.1.0", URL: "https://example.com/compare/1.0.0...1.1.0"},
+			},
+		},
+		{
+			name:     "unreleased and oldest release",
+			releases: []*Release{doc.FindRelease("Unreleased"), doc.FindRelease("1.0.0")},
+			want: []ExportedLink{
+				{Label: "Unreleased", URL: "https://example.com/compare/1.1.0...HEAD"},
+				{Label: "1.0.0", URL: "https://example.com/releases/1.0.0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exported := ExportReleases(doc, tt.releases)
+			if !reflect.DeepEqual(exported.Links, tt.want) {
+				t.Errorf("ExportReleases() links = %+v, want %+v", exported.Links, tt.want)
+			}
+		})
+	}
+}
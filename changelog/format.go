@@ -0,0 +1,117 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"path/filepath"
+	"strings"
+)
+
+// Format is the markup format of a changelog file.
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatAsciiDoc Format = "asciidoc"
+	FormatRST      Format = "rst"
+	FormatDebian   Format = "debian"
+	FormatRPM      Format = "rpm"
+)
+
+// Formats lists the supported changelog formats.
+var Formats = []Format{FormatMarkdown, FormatAsciiDoc, FormatRST, FormatDebian, FormatRPM}
+
+// IsPackage returns true for the formats of package changelogs, in which
+// every release must have a valid package version.
+func (f Format) IsPackage() bool {
+	return f == FormatDebian || f == FormatRPM
+}
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+	var names []string
+	for _, format := range Formats {
+		names = append(names, string(format))
+	}
+	return "", fmt.Errorf("unsupported changelog format: %s, must be one of: %s", name, strings.Join(names, ", "))
+}
+
+// DetectFormat returns the format of a changelog file, based on its name.
+// Files that are not recognised are assumed to be Markdown.
+func DetectFormat(path string) Format {
+	base := strings.ToLower(filepath.Base(path))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".adoc", ".asciidoc":
+		return FormatAsciiDoc
+	case ".rst":
+		return FormatRST
+	case ".spec":
+		return FormatRPM
+	}
+	if base == "changelog" && filepath.Base(filepath.Dir(path)) == "debian" {
+		return FormatDebian
+	}
+	return FormatMarkdown
+}
+
+// ResolveFormat returns the format set in the config, if any, otherwise
+// the format detected from the name of the changelog file.
+func ResolveFormat(config cfg.SinceConfig, changelogFile string) (Format, error) {
+	if config.Changelog.Format != "" {
+		return ParseFormat(config.Changelog.Format)
+	}
+	return DetectFormat(changelogFile), nil
+}
+
+// ParseDocumentAs parses the lines of a changelog file in the given format
+// into a Document.
+func ParseDocumentAs(lines []string, format Format) *Document {
+	switch format {
+	case FormatAsciiDoc:
+		return parseWithSyntax(lines, asciiDocSyntax{})
+	case FormatRST:
+		return parseWithSyntax(lines, rstSyntax{})
+	case FormatDebian:
+		return parseWithSyntax(lines, debianSyntax{})
+	case FormatRPM:
+		return parseRPMChangelog(lines)
+	default:
+		return ParseDocument(lines)
+	}
+}
+
+// changelogBoilerplate returns the content of a new changelog file in
+// the given format. Package changelogs have no boilerplate.
+func changelogBoilerplate(format Format) string {
+	switch format {
+	case FormatAsciiDoc:
+		return asciiDocChangelogTemplate
+	case FormatRST:
+		return rstChangelogTemplate
+	case FormatDebian, FormatRPM:
+		return ""
+	default:
+		return changelogTemplate
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]Format{
+		"CHANGELOG.md":              FormatMarkdown,
+		"CHANGES":                   FormatMarkdown,
+		"docs/changelog.adoc":       FormatAsciiDoc,
+		"docs/CHANGELOG.rst":        FormatRST,
+		"debian/changelog":          FormatDebian,
+		"/src/pkg/debian/changelog": FormatDebian,
+		"packaging/since.spec":      FormatRPM,
+	}
+	for path, want := range tests {
+		if got := DetectFormat(path); got != want {
+			t.Errorf("DetectFormat(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	got, err := ResolveFormat(cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Format: "Debian"}}, "CHANGELOG.md")
+	if err != nil || got != FormatDebian {
+		t.Errorf("ResolveFormat() = %v, %v, want %v", got, err, FormatDebian)
+	}
+	if _, err := ResolveFormat(cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Format: "html"}}, "CHANGELOG.md"); err == nil {
+		t.Error("ResolveFormat() expected error for unsupported format")
+	}
+}
+
+func TestRenderer_formats(t *testing.T) {
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: "v1.2.0", Date: time.Date(2023, 8, 28, 10, 30, 0, 0, time.UTC)},
+			Commits: []string{"feat: 50% faster", "fix: bar"},
+			Details: []vcs.CommitDetail{
+				{Message: "feat: 50% faster", Author: "Jane Doe", AuthorEmail: "jane@example.com"},
+				{Message: "fix: bar", Author: "Joe", AuthorEmail: "joe@example.com"},
+			},
+		},
+	}
+	config := cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Package: cfg.PackageConfig{Name: "since", Urgency: "low"}}}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatAsciiDoc,
+			want: `== [1.2.0] - 2023-08-28
+=== Added
+* feat: 50% faster
+
+=== Fixed
+* fix: bar`,
+		},
+		{
+			format: FormatRST,
+			want: `1.2.0 - 2023-08-28
+------------------
+
+Added
+~~~~~
+
+- feat: 50% faster
+
+Fixed
+~~~~~
+
+- fix: bar`,
+		},
+		{
+			format: FormatDebian,
+			want: `since (1.2.0) unstable; urgency=low
+
+  [ Added ]
+  * feat: 50% faster
+
+  [ Fixed ]
+  * fix: bar
+
+ -- Jane Doe <jane@example.com>  Mon, 28 Aug 2023 10:30:00 +0000`,
+		},
+		{
+			format: FormatRPM,
+			want: `* Mon Aug 28 2023 Jane Doe <jane@example.com> - 1.2.0
+- feat: 50%% faster
+- fix: bar`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			renderer, err := NewRenderer(config, ".", tt.format)
+			if err != nil {
+				t.Fatalf("NewRenderer() error = %v", err)
+			}
+			got, err := renderer.Render(&commits, true, false, vcs.UnreleasedVersionName)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() got = %v, want %v", got, tt.want)
+			}
+
+			// the rendered release can be read back in the same format
+			doc := ParseDocumentAs(strings.Split(got, "\n"), tt.format)
+			if len(doc.Releases) != 1 || doc.Releases[0].Version != "1.2.0" || doc.Releases[0].Date != "2023-08-28" {
+				t.Fatalf("ParseDocumentAs() releases = %+v, want 1.2.0 on 2023-08-28", doc.Releases)
+			}
+			var entries []string
+			for _, section := range doc.Releases[0].Sections {
+				for _, entry := range section.Entries {
+					entries = append(entries, entry.Text)
+				}
+			}
+			if want := []string{"feat: 50% faster", "fix: bar"}; !reflect.DeepEqual(entries, want) {
+				t.Errorf("ParseDocumentAs() entries = %v, want %v", entries, want)
+			}
+		})
+	}
+}
+
+func TestRenderer_unreleasedPackageFormats(t *testing.T) {
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: vcs.UnreleasedVersionName, Date: time.Date(2023, 8, 28, 10, 30, 0, 0, time.UTC)},
+			Commits: []string{"feat: foo"},
+			Details: []vcs.CommitDetail{{Message: "feat: foo", Author: "Jane Doe", AuthorEmail: "jane@example.com"}},
+		},
+	}
+	config := cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Package: cfg.PackageConfig{Name: "since"}}}
+
+	tests := []struct {
+		format      Format
+		nextVersion string
+		want        string
+		wantErr     bool
+	}{
+		{
+			format:      FormatDebian,
+			nextVersion: "1.3.0",
+			want: `since (1.3.0~unreleased) UNRELEASED; urgency=medium
+
+  [ Added ]
+  * feat: foo
+
+ -- Jane Doe <jane@example.com>  Mon, 28 Aug 2023 10:30:00 +0000`,
+		},
+		{
+			format:      FormatRPM,
+			nextVersion: "1.3.0",
+			want: `* Mon Aug 28 2023 Jane Doe <jane@example.com> - 1.3.0~unreleased
+- feat: foo`,
+		},
+		{
+			format:  FormatDebian,
+			wantErr: true,
+		},
+		{
+			format:  FormatRPM,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.format, tt.nextVersion), func(t *testing.T) {
+			renderer, err := NewRenderer(config, ".", tt.format)
+			if err != nil {
+				t.Fatalf("NewRenderer() error = %v", err)
+			}
+			renderer.NextVersion = tt.nextVersion
+			got, err := renderer.Render(&commits, true, false, vcs.UnreleasedVersionName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() got = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// the rendered release is read back as unreleased
+			doc := ParseDocumentAs(strings.Split(got, "\n"), tt.format)
+			if len(doc.Releases) != 1 || !doc.Releases[0].IsUnreleased() {
+				t.Errorf("ParseDocumentAs() releases = %+v, want one unreleased release", doc.Releases)
+			}
+		})
+	}
+}
+
+func TestParseDocumentAs(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    Format
+		changelog string
+		want      []string
+	}{
+		{
+			name:   "debian without sections",
+			format: FormatDebian,
+			changelog: `since (0.2.0-1) unstable; urgency=medium
+
+  * New upstream release.
+    Closes: #1234
+  * Update standards version.
+
+ -- Jane Doe <jane@example.com>  Tue, 05 Mar 2024 12:00:00 +0100
+
+since (0.1.0-1) unstable; urgency=low
+
+  * Initial release.
+
+ -- Jane Doe <jane@example.com>  Mon, 04 Mar 2024 12:00:00 +0100`,
+			want: []string{
+				"0.2.0-1 2024-03-05",
+				" New upstream release. [Closes: #1234]",
+				" Update standards version.",
+				"0.1.0-1 2024-03-04",
+				" Initial release.",
+			},
+		},
+		{
+			name:   "rpm spec file",
+			format: FormatRPM,
+			changelog: `Name: since
+Version: 0.2.0
+
+%description
+* not a changelog entry
+
+%changelog
+* Tue Mar  5 2024 Jane Doe <jane@example.com> - 0.2.0-1
+- New upstream release
+
+* Mon Mar 04 2024 Jane Doe <jane@example.com> - 0.1.0-1
+- Initial release`,
+			want: []string{
+				"0.2.0-1 2024-03-05",
+				" New upstream release",
+				"0.1.0-1 2024-03-04",
+				" Initial release",
+			},
+		},
+		{
+			name:   "rpm spec file with sections after the changelog",
+			format: FormatRPM,
+			changelog: `Name: since
+
+%changelog
+* Mon Mar 04 2024 Jane Doe <jane@example.com> - 0.1.0-1
+- Initial release
+
+%files
+* Mon Mar 04 2024 not a release - 0.0.1
+- /usr/bin/since`,
+			want: []string{
+				"0.1.0-1 2024-03-04",
+				" Initial release",
+			},
+		},
+		{
+			name:   "asciidoc",
+			format: FormatAsciiDoc,
+			changelog: `= Changelog
+
+== [Unreleased]
+
+== [1.0.0] - 2024-01-01
+=== Added
+* First feature
+** with a nested point`,
+			want: []string{
+				"Unreleased ",
+				"1.0.0 2024-01-01",
+				"Added First feature",
+				"Added with a nested point",
+			},
+		},
+		{
+			name:   "reStructuredText",
+			format: FormatRST,
+			changelog: `Changelog
+=========
+
+1.0.0 - 2024-01-01
+------------------
+
+Fixed
+~~~~~
+
+- A bug
+  over two lines`,
+			want: []string{
+				"1.0.0 2024-01-01",
+				"Fixed A bug [over two lines]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(tt.changelog, "\n")
+			doc := ParseDocumentAs(lines, tt.format)
+
+			var got []string
+			for _, release := range doc.Releases {
+				got = append(got, release.Version+" "+release.Date)
+				for _, section := range release.Sections {
+					for _, entry := range section.Entries {
+						text := section.Name + " " + entry.Text
+						if len(entry.Details) > 0 {
+							text += " [" + strings.Join(entry.Details, " ") + "]"
+						}
+						got = append(got, text)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDocumentAs() = %q, want %q", got, tt.want)
+			}
+			if rendered := doc.Render(); rendered != tt.changelog {
+				t.Errorf("Render() = %v, want %v", rendered, tt.changelog)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/semver"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultFragmentsDir is the directory of changelog fragment files,
+// relative to the repository root, if none is configured.
+const DefaultFragmentsDir = ".changes"
+
+// fragmentNameRegex matches the names of fragment files, such as
+// '1234-import-projects.md', so that other files in the directory, such
+// as a README.md, are not read as fragments.
+var fragmentNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*\.md$`)
+
+// Fragment is a changelog entry written in a file, for a change that is
+// not described by a single commit. A fragment is a Markdown file with
+// optional YAML front-matter setting its section and version bump:
+//
+//	---
+//	section: Added
+//	bump: minor
+//	---
+//	Support for importing projects from other tools.
+//
+// The first paragraph is the entry, and the lines of any further
+// paragraphs are notes listed under it.
+type Fragment struct {
+	// Path is the path of the fragment file.
+	Path string
+
+	// Section is the section of the entry, or empty for the Other section.
+	Section string
+
+	// Bump is the version bump required by the change, which is a patch
+	// release if not set.
+	Bump semver.Component
+
+	Text  string
+	Notes []string
+
+	// Modified is the time the fragment file was last modified, which
+	// orders its entry chronologically.
+	Modified time.Time
+}
+
+type fragmentFrontMatter struct {
+	Section string `yaml:"section"`
+	Bump    string `yaml:"bump"`
+}
+
+// ReadFragments returns the fragments in the fragments directory of the
+// repository, sorted by file name. Fragment file names are made of lower
+// case letters, digits, hyphens and underscores, with an '.md' extension.
+// If the directory does not exist, there are no fragments.
+func ReadFragments(config cfg.SinceConfig, repoPath string) ([]Fragment, error) {
+	dir := config.Changelog.FragmentsDir
+	if dir == "" {
+		dir = DefaultFragmentsDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var fragments []Fragment
+	for _, file := range files {
+		if !fragmentNameRegex.MatchString(filepath.Base(file)) {
+			logrus.Tracef("ignoring file in fragments directory: %s", file)
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read changelog fragment: %w", err)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read changelog fragment: %w", err)
+		}
+		fragment, err := parseFragment(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid changelog fragment: %s: %w", file, err)
+		}
+		fragment.Path = file
+		fragment.Modified = info.ModTime()
+		fragments = append(fragments, fragment)
+	}
+	logrus.Debugf("read %d changelog fragments from %s", len(fragments), dir)
+	return fragments, nil
+}
+
+// parseFragment parses the front-matter and entry of a fragment file.
+func parseFragment(content string) (Fragment, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	var frontMatter fragmentFrontMatter
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		end := -1
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return Fragment{}, fmt.Errorf("front-matter is not closed with '---'")
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &frontMatter); err != nil {
+			return Fragment{}, fmt.Errorf("failed to parse front-matter: %w", err)
+		}
+		lines = lines[end+1:]
+	}
+
+	fragment := Fragment{Section: frontMatter.Section, Bump: semver.ComponentPatch}
+	if frontMatter.Bump != "" {
+		bump, err := semver.ParseComponent(frontMatter.Bump)
+		if err != nil {
+			return Fragment{}, err
+		}
+		fragment.Bump = bump
+	}
+
+	var entry []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			if len(entry) > 0 && fragment.Text == "" {
+				fragment.Text = strings.Join(entry, " ")
+			}
+		case fragment.Text == "":
+			entry = append(entry, line)
+		default:
+			fragment.Notes = append(fragment.Notes, line)
+		}
+	}
+	if fragment.Text == "" {
+		fragment.Text = strings.Join(entry, " ")
+	}
+	if fragment.Text == "" {
+		return Fragment{}, fmt.Errorf("fragment has no entry")
+	}
+	return fragment, nil
+}
+
+// fragmentsBump returns the largest version bump of the fragments.
+func fragmentsBump(fragments []Fragment) semver.Component {
+	bump := semver.ComponentNone
+	for _, fragment := range fragments {
+		bump = semver.MaxComponent(bump, fragment.Bump)
+	}
+	return bump
+}
+
+// fragmentEntries returns the entries of the fragments, as template data
+// grouped by section, in the order of the fragments. Entries without a
+// section are in the Other section. The text of a fragment is used as it
+// is written.
+func fragmentEntries(fragments []Fragment) []SectionData {
+	var sections []SectionData
+	for _, fragment := range fragments {
+		name := fragment.Section
+		if name == "" {
+			name = otherSectionName
+		}
+		entry := CommitData{
+			Message:     fragment.Text,
+			Description: fragment.Text,
+			Text:        fragment.Text,
+			Notes:       fragment.Notes,
+			Committed:   fragment.Modified,
+		}
+		found := false
+		for i := range sections {
+			if strings.EqualFold(sections[i].Name, name) {
+				sections[i].Commits = append(sections[i].Commits, entry)
+				found = true
+				break
+			}
+		}
+		if !found {
+			sections = append(sections, SectionData{Name: name, Commits: []CommitData{entry}})
+		}
+	}
+	return sections
+}
+
+// fragmentPaths returns the paths of the fragment files.
+func fragmentPaths(fragments []Fragment) []string {
+	var paths []string
+	for _, fragment := range fragments {
+		paths = append(paths, fragment.Path)
+	}
+	return paths
+}
+
+// RemoveFragments deletes the fragment files, once they have been
+// written to the changelog.
+func RemoveFragments(paths []string) error {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove changelog fragment: %w", err)
+		}
+	}
+	if len(paths) > 0 {
+		logrus.Debugf("removed %d changelog fragments", len(paths))
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/semver"
+	"github.com/release-tools/since/vcs"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseFragment(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Fragment
+		wantErr bool
+	}{
+		{
+			name:    "entry only",
+			content: "Support for importing projects.\n",
+			want:    Fragment{Bump: semver.ComponentPatch, Text: "Support for importing projects."},
+		},
+		{
+			name:    "front-matter",
+			content: "---\nsection: Added\nbump: minor\n---\nSupport for importing projects.\n",
+			want:    Fragment{Section: "Added", Bump: semver.ComponentMinor, Text: "Support for importing projects."},
+		},
+		{
+			name:    "entry over several lines with notes",
+			content: "---\nbump: major\n---\n\nRemoves the legacy\nimport format.\n\nMigrate with the convert command.\nSee the docs.\n",
+			want: Fragment{
+				Bump:  semver.ComponentMajor,
+				Text:  "Removes the legacy import format.",
+				Notes: []string{"Migrate with the convert command.", "See the docs."},
+			},
+		},
+		{
+			name:    "windows line endings",
+			content: "---\r\nsection: Fixed\r\n---\r\nA crash on start.\r\n",
+			want:    Fragment{Section: "Fixed", Bump: semver.ComponentPatch, Text: "A crash on start."},
+		},
+		{
+			name:    "invalid bump",
+			content: "---\nbump: huge\n---\nA change.\n",
+			wantErr: true,
+		},
+		{
+			name:    "unclosed front-matter",
+			content: "---\nsection: Added\nA change.\n",
+			wantErr: true,
+		},
+		{
+			name:    "no entry",
+			content: "---\nsection: Added\n---\n\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFragment(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFragment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFragment() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFragments(t *testing.T) {
+	repoDir := t.TempDir()
+	if fragments, err := ReadFragments(cfg.SinceConfig{}, repoDir); err != nil || len(fragments) != 0 {
+		t.Fatalf("ReadFragments() without a fragments directory = %v, %v, want none", fragments, err)
+	}
+
+	dir := path.Join(repoDir, "changes")
+	writeFragment(t, dir, "b-fix.md", "---\nsection: Fixed\n---\nA crash on start.\n")
+	writeFragment(t, dir, "a-feature.md", "---\nbump: minor\n---\nSupport for importing projects.\n")
+	writeFragment(t, dir, "README.txt", "Not a fragment.\n")
+	writeFragment(t, dir, "README.md", "Describe each change in a file of this directory.\n")
+
+	config := cfg.SinceConfig{Changelog: cfg.ChangelogConfig{FragmentsDir: "changes"}}
+	fragments, err := ReadFragments(config, repoDir)
+	if err != nil {
+		t.Fatalf("ReadFragments() error = %v", err)
+	}
+	want := []string{path.Join(dir, "a-feature.md"), path.Join(dir, "b-fix.md")}
+	if got := fragmentPaths(fragments); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadFragments() paths = %v, want %v", got, want)
+	}
+
+	bump, err := MinimumBump(config, repoDir, path.Join(repoDir, "CHANGELOG.md"))
+	if err != nil {
+		t.Fatalf("MinimumBump() error = %v", err)
+	}
+	if bump != semver.ComponentMinor {
+		t.Errorf("MinimumBump() = %v, want %v", bump, semver.ComponentMinor)
+	}
+
+	writeFragment(t, dir, "c-invalid.md", "---\nbump: huge\n---\nA change.\n")
+	if _, err := ReadFragments(config, repoDir); err == nil {
+		t.Errorf("ReadFragments() with an invalid fragment, want error")
+	}
+}
+
+func TestGetUpdatedChangelog_fragments(t *testing.T) {
+	repoDir := createTestRepo(t)
+	commitChange(t, repoDir, "CHANGELOG.md", changelogTemplate, "docs: adds changelog", time.Now())
+	writeFragment(t, path.Join(repoDir, DefaultFragmentsDir), "import.md", "---\nsection: Added\nbump: minor\n---\nSupport for importing projects.\n\nRun the import command.\n")
+
+	metadata, updated, err := GetUpdatedChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, path.Join(repoDir, "CHANGELOG.md"), vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetUpdatedChangelog() error = %v", err)
+	}
+	if metadata.NewVersion != "0.2.0" {
+		t.Errorf("GetUpdatedChangelog() version = %v, want 0.2.0", metadata.NewVersion)
+	}
+	wantFragments := []string{path.Join(repoDir, DefaultFragmentsDir, "import.md")}
+	if !reflect.DeepEqual(metadata.Fragments, wantFragments) {
+		t.Errorf("GetUpdatedChangelog() fragments = %v, want %v", metadata.Fragments, wantFragments)
+	}
+
+	want := fmt.Sprintf(`## [0.2.0] - %v
+### Added
+- Support for importing projects.
+  Run the import command.
+
+### Changed
+- docs: adds changelog
+`, time.Now().Format("2006-01-02"))
+	if !strings.Contains(updated, want) {
+		t.Errorf("GetUpdatedChangelog() missing fragment entry, got:\n%s", updated)
+	}
+}
+
+func TestGetUpdatedChangelog_onlyFragments(t *testing.T) {
+	repoDir := createTestRepo(t)
+	if err := os.WriteFile(path.Join(repoDir, "CHANGELOG.md"), []byte(changelogTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := GetUpdatedChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, path.Join(repoDir, "CHANGELOG.md"), vcs.TagOrderSemver, repoDir, "", "0.1.0"); err == nil {
+		t.Fatalf("GetUpdatedChangelog() without commits or fragments, want error")
+	}
+
+	writeFragment(t, path.Join(repoDir, DefaultFragmentsDir), "fix.md", "---\nsection: Fixed\n---\nA crash on start.\n")
+	metadata, updated, err := GetUpdatedChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, path.Join(repoDir, "CHANGELOG.md"), vcs.TagOrderSemver, repoDir, "", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetUpdatedChangelog() error = %v", err)
+	}
+	if metadata.NewVersion != "0.1.1" {
+		t.Errorf("GetUpdatedChangelog() version = %v, want 0.1.1", metadata.NewVersion)
+	}
+	if !strings.Contains(updated, "### Fixed\n- A crash on start.\n") {
+		t.Errorf("GetUpdatedChangelog() missing fragment entry, got:\n%s", updated)
+	}
+}
+
+func Test_fragmentEntries(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fragments := []Fragment{
+		{Text: "docs: how to import projects.", Modified: modified},
+		{Section: "added", Text: "Support for importing projects.", Notes: []string{"Run the import command."}, Modified: modified},
+		{Text: "skip", Modified: modified},
+	}
+	want := []SectionData{
+		{Name: otherSectionName, Commits: []CommitData{
+			{Message: "docs: how to import projects.", Description: "docs: how to import projects.", Text: "docs: how to import projects.", Committed: modified},
+			{Message: "skip", Description: "skip", Text: "skip", Committed: modified},
+		}},
+		{Name: "added", Commits: []CommitData{
+			{Message: "Support for importing projects.", Description: "Support for importing projects.", Text: "Support for importing projects.", Notes: []string{"Run the import command."}, Committed: modified},
+		}},
+	}
+	if got := fragmentEntries(fragments); !reflect.DeepEqual(got, want) {
+		t.Errorf("fragmentEntries() = %v, want %v", got, want)
+	}
+}
+
+func writeFragment(t *testing.T, dir string, name string, content string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/rogpeppe/go-internal/semver"
+	"golang.org/x/exp/slices"
+	"strings"
+	"time"
+)
+
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue is a problem found in a changelog.
+type LintIssue struct {
+	Line     int          `json:"line"`
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+}
+
+// KnownSectionNames lists the section names recognised by the linter: those
+// defined by Keep a Changelog, plus the 'Other', breaking changes and
+// contributors sections that since generates.
+var KnownSectionNames = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security", "Other", BreakingChangesSectionName, ContributorsSectionName}
+
+// Lint checks that the document is a well-formed Keep a Changelog file,
+// returning the issues found in line order. Sections named in allowedSections
+// are accepted in addition to KnownSectionNames.
+func Lint(doc *Document, allowedSections []string) []LintIssue {
+	var issues []LintIssue
+	report := func(line int, rule string, severity LintSeverity, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Line: line, Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !hasTitle(doc.Preamble) {
+		report(1, "missing-title", LintWarning, "changelog should start with a level 1 heading")
+	}
+	if len(doc.Releases) == 0 {
+		report(1, "no-releases", LintError, "changelog contains no version sections")
+	}
+
+	seen := make(map[string]int)
+	var previous *Release
+	for _, release := range doc.Releases {
+		if release.IsUnreleased() {
+			if release != doc.Releases[0] {
+				report(release.Line, "unreleased-position", LintError, "Unreleased section should be the first section")
+			}
+		} else if lintVersion(release, report) {
+			version := semver.Canonical(canonicalVersion(release.Version))
+			if line, ok := seen[version]; ok {
+				report(release.Line, "duplicate-version", LintError, "version %s is already listed on line %d", release.Version, line)
+			} else {
+				seen[version] = release.Line
+			}
+			if previous != nil && semver.Compare(canonicalVersion(release.Version), canonicalVersion(previous.Version)) > 0 {
+				report(release.Line, "version-order", LintError, "version %s should be listed before %s", release.Version, previous.Version)
+			}
+			previous = release
+		}
+
+		for _, section := range release.Sections {
+			if !slices.Contains(KnownSectionNames, section.Name) && !slices.Contains(allowedSections, section.Name) {
+				report(section.Line, "unknown-section", LintWarning, "unknown section name '%s', expected one of: %s", section.Name, strings.Join(KnownSectionNames, ", "))
+			}
+			if len(section.Entries) == 0 {
+				report(section.Line, "empty-section", LintWarning, "section '%s' of %s has no entries", section.Name, release.Version)
+			}
+		}
+	}
+
+	for _, link := range doc.Links {
+		if doc.FindRelease(link.Label) == nil {
+			report(link.Line, "dangling-link", LintWarning, "link reference '%s' does not match any version", link.Label)
+		}
+	}
+
+	slices.SortStableFunc(issues, func(a, b LintIssue) bool {
+		return a.Line < b.Line
+	})
+	return issues
+}
+
+// lintVersion checks the version and date of a release heading,
+// returning true if the version is valid semver.
+func lintVersion(release *Release, report func(int, string, LintSeverity, string, ...interface{})) bool {
+	// semver accepts shorthand such as 'v1.2', which is not a full version
+	version := canonicalVersion(release.Version)
+	valid := semver.IsValid(version) && semver.Canonical(version) == strings.SplitN(version, "+", 2)[0]
+	if !valid {
+		report(release.Line, "invalid-version", LintError, "'%s' is not a valid semantic version", release.Version)
+	}
+	if release.Date == "" {
+		report(release.Line, "missing-date", LintError, "version %s has no release date", release.Version)
+	} else if _, err := time.Parse("2006-01-02", release.Date); err != nil {
+		report(release.Line, "invalid-date", LintError, "'%s' is not a valid ISO 8601 date (YYYY-MM-DD)", release.Date)
+	}
+	return valid
+}
+
+// hasTitle returns true if the lines contain a level 1 heading.
+func hasTitle(lines []string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, "# ") {
+			return true
+		}
+	}
+	return false
+}
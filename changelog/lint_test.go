@@ -0,0 +1,146 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name            string
+		changelog       string
+		allowedSections []string
+		want            []string
+	}{
+		{
+			name: "valid changelog",
+			changelog: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: next feature.
+
+## [1.1.0] - 2023-03-06
+### Fixed
+- fix: a bug.
+
+## [1.0.0] - 2023-03-05
+### Added
+- feat: first feature.
+
+[Unreleased]: https://example.com/compare/1.1.0...HEAD
+[1.1.0]: https://example.com/compare/1.0.0...1.1.0
+`,
+		},
+		{
+			name:      "no title or releases",
+			changelog: "Some notes.\n",
+			want:      []string{"1:missing-title", "1:no-releases"},
+		},
+		{
+			name: "invalid versions and dates",
+			changelog: `# Changelog
+
+## [1.0] - 2023-03-05
+### Added
+- feat: a.
+
+## [0.9.0]
+### Added
+- feat: b.
+
+## [0.8.0] - 2023/03/01
+### Added
+- feat: c.
+`,
+			want: []string{"3:invalid-version", "7:missing-date", "11:invalid-date"},
+		},
+		{
+			name: "order, duplicates and unreleased position",
+			changelog: `# Changelog
+
+## [1.0.0] - 2023-03-05
+### Added
+- feat: a.
+
+## [Unreleased]
+### Added
+- feat: b.
+
+## [v1.1.0] - 2023-03-06
+### Added
+- feat: c.
+
+## [v1.0.0] - 2023-03-05
+### Added
+- feat: d.
+`,
+			want: []string{"7:unreleased-position", "11:version-order", "15:duplicate-version"},
+		},
+		{
+			name: "sections and links",
+			changelog: `# Changelog
+
+## [1.0.0] - 2023-03-05
+### Added
+
+### Misc
+- chore: a.
+
+### Extras
+- chore: b.
+
+[1.0.0]: https://example.com/1.0.0
+[0.9.0]: https://example.com/0.9.0
+`,
+			allowedSections: []string{"Extras"},
+			want:            []string{"4:empty-section", "6:unknown-section", "13:dangling-link"},
+		},
+		{
+			name: "links match versions case-insensitively",
+			changelog: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: c.
+
+## [1.0.0] - 2023-03-05
+### Added
+- feat: a.
+
+[unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/1.0.0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := ParseDocument(strings.Split(tt.changelog, "\n"))
+			var got []string
+			for _, issue := range Lint(doc, tt.allowedSections) {
+				got = append(got, fmt.Sprintf("%d:%s", issue.Line, issue.Rule))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,564 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/vcs"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// MergeConflictError is returned when the changes to a changelog on
+// each side of a merge cannot be combined.
+type MergeConflictError struct {
+	Conflicts []string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("changelog merge conflict: %s", strings.Join(e.Conflicts, "; "))
+}
+
+// changelogMerge holds the state of a three-way merge of changelogs.
+type changelogMerge struct {
+	conflicts []string
+}
+
+// MergeChangelogs performs a three-way merge of a Markdown changelog,
+// given the content of the common ancestor and of each side. The entries
+// of each release and section are combined, so entries added on both
+// sides are kept, and entries removed on either side are removed. Parts
+// of the changelog that only changed on one side take that side's
+// version. If both sides changed the same part differently, such as the
+// date of a release, a MergeConflictError listing the conflicts is
+// returned.
+func MergeChangelogs(base string, ours string, theirs string) (string, error) {
+	trailingNewline := strings.HasSuffix(ours, "\n")
+	baseDoc := parseMergeInput(base)
+	oursDoc := parseMergeInput(ours)
+	theirsDoc := parseMergeInput(theirs)
+
+	m := &changelogMerge{}
+	merged := &Document{}
+	merged.Preamble = m.pickLines("the preamble", baseDoc.Preamble, oursDoc.Preamble, theirsDoc.Preamble)
+	merged.Releases = m.mergeReleases(baseDoc, oursDoc, theirsDoc)
+	merged.Footer = m.mergeFooter(baseDoc, oursDoc, theirsDoc)
+	if len(m.conflicts) > 0 {
+		return "", &MergeConflictError{Conflicts: m.conflicts}
+	}
+
+	result := merged.Render()
+	if trailingNewline {
+		result += "\n"
+	}
+	logrus.Debugf("merged changelog with %d releases", len(merged.Releases))
+	return result, nil
+}
+
+// parseMergeInput parses the content of a changelog, without its
+// final newline.
+func parseMergeInput(content string) *Document {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return &Document{}
+	}
+	return ParseDocument(strings.Split(content, "\n"))
+}
+
+// conflict records a conflict between the two sides.
+func (m *changelogMerge) conflict(format string, args ...interface{}) {
+	m.conflicts = append(m.conflicts, fmt.Sprintf(format, args...))
+}
+
+// pickLines returns the side that changed the lines, or records a
+// conflict if both sides changed them differently.
+func (m *changelogMerge) pickLines(name string, base []string, ours []string, theirs []string) []string {
+	merged, ok := pick(base, ours, theirs)
+	if !ok {
+		m.conflict("%s was changed on both sides", name)
+	}
+	return merged
+}
+
+// pick returns the side that changed the lines, or false if both sides
+// changed them differently.
+func pick(base []string, ours []string, theirs []string) ([]string, bool) {
+	switch {
+	case linesEqual(ours, theirs), linesEqual(base, theirs):
+		return ours, true
+	case linesEqual(base, ours):
+		return theirs, true
+	default:
+		return nil, false
+	}
+}
+
+// mergeReleases merges the releases of the documents. The Unreleased
+// section is always kept first.
+func (m *changelogMerge) mergeReleases(base *Document, ours *Document, theirs *Document) []*Release {
+	versions := mergeKeys(releaseKeys(ours.Releases), releaseKeys(theirs.Releases))
+	unreleased := releaseKey(vcs.UnreleasedVersionName)
+	if i := indexOf(versions, unreleased); i > 0 {
+		versions = append([]string{unreleased}, append(versions[:i:i], versions[i+1:]...)...)
+	}
+
+	var releases []*Release
+	for _, version := range versions {
+		release := m.mergeRelease(version, findReleaseByKey(base, version), findReleaseByKey(ours, version), findReleaseByKey(theirs, version))
+		if release != nil {
+			releases = append(releases, release)
+		}
+	}
+	for i, release := range releases {
+		if i < len(releases)-1 {
+			ensureTrailingBlankLine(release)
+		}
+	}
+	return releases
+}
+
+// releaseKey returns the key of a version when merging releases,
+// ignoring case and any leading 'v'.
+func releaseKey(version string) string {
+	return strings.ToLower(strings.TrimPrefix(version, "v"))
+}
+
+// releaseKeys returns the keys of the versions of the releases.
+func releaseKeys(releases []*Release) []string {
+	var keys []string
+	for _, release := range releases {
+		keys = append(keys, releaseKey(release.Version))
+	}
+	return keys
+}
+
+// findReleaseByKey returns the release of the document with the given
+// key, or nil if there is none.
+func findReleaseByKey(doc *Document, key string) *Release {
+	for _, release := range doc.Releases {
+		if releaseKey(release.Version) == key {
+			return release
+		}
+	}
+	return nil
+}
+
+// mergeRelease merges a release, returning nil if it was removed.
+func (m *changelogMerge) mergeRelease(version string, base *Release, ours *Release, theirs *Release) *Release {
+	switch {
+	case ours == nil && theirs == nil:
+		return nil
+	case ours != nil && theirs != nil && linesEqual(ours.Lines(), theirs.Lines()):
+		return ours
+	case base != nil && ours != nil && linesEqual(base.Lines(), ours.Lines()):
+		// only changed, or removed, by theirs
+		return theirs
+	case base != nil && theirs != nil && linesEqual(base.Lines(), theirs.Lines()):
+		return ours
+	case base == nil && ours == nil:
+		return theirs
+	case base == nil && theirs == nil:
+		return ours
+	}
+
+	// both sides changed the release, or one removed it while the other changed it
+	heading := ours.headingOr(theirs)
+	if ours != nil && theirs != nil {
+		if picked, ok := pick(releaseHeading(base), []string{ours.Heading}, []string{theirs.Heading}); ok {
+			heading = picked[0]
+		} else if ours.Date != theirs.Date {
+			m.conflict("version %s has different dates: %s and %s", ours.Version, ours.Date, theirs.Date)
+		} else {
+			m.conflict("the heading of version %s was changed on both sides", ours.Version)
+		}
+	}
+	merged := parseReleaseHeading(heading)
+
+	intro := m.mergeSection(merged.Version, "", introSection(base), introSection(ours), introSection(theirs))
+	if intro != nil {
+		merged.Intro = intro.Lines
+	}
+	names := mergeKeys(sectionKeys(ours), sectionKeys(theirs))
+	for _, name := range names {
+		section := m.mergeSection(merged.Version, name, findSectionOf(base, name), findSectionOf(ours, name), findSectionOf(theirs, name))
+		if section != nil {
+			merged.Sections = append(merged.Sections, section)
+		}
+	}
+
+	if ours == nil || theirs == nil {
+		// removed on one side: only keep the release for the changes made by the other
+		if !hasEntries(merged) {
+			logrus.Debugf("dropping release %s removed on one side of the merge", version)
+			return nil
+		}
+	}
+	separateSections(merged)
+	return merged
+}
+
+// headingOr returns the heading of the release, or of the other release
+// if this one is nil.
+func (r *Release) headingOr(other *Release) string {
+	if r == nil {
+		return other.Heading
+	}
+	return r.Heading
+}
+
+// releaseHeading returns the heading of the release as a line, or no
+// lines if there is no release.
+func releaseHeading(release *Release) []string {
+	if release == nil {
+		return nil
+	}
+	return []string{release.Heading}
+}
+
+// introSection returns the lines between the heading of the release
+// and its first section as a section without a heading.
+func introSection(release *Release) *Section {
+	if release == nil || len(release.Intro) == 0 {
+		return nil
+	}
+	section := &Section{Lines: release.Intro}
+	for _, line := range release.Intro {
+		section.addLine(strings.TrimRight(line, "\r"))
+	}
+	return section
+}
+
+// sectionKeys returns the names of the sections of the release.
+func sectionKeys(release *Release) []string {
+	if release == nil {
+		return nil
+	}
+	var keys []string
+	for _, section := range release.Sections {
+		keys = append(keys, strings.ToLower(section.Name))
+	}
+	return keys
+}
+
+// findSectionOf returns the section of the release with the given name,
+// or nil if there is no release or section.
+func findSectionOf(release *Release, name string) *Section {
+	if release == nil {
+		return nil
+	}
+	return findSection(release, name)
+}
+
+// mergeSection merges a section, returning nil if it was removed.
+// Sections changed on both sides are combined entry by entry.
+func (m *changelogMerge) mergeSection(version string, name string, base *Section, ours *Section, theirs *Section) *Section {
+	switch {
+	case ours == nil && theirs == nil:
+		return nil
+	case ours != nil && theirs != nil && sectionsEqual(ours, theirs):
+		return ours
+	case base != nil && ours != nil && sectionsEqual(base, ours):
+		return theirs
+	case base != nil && theirs != nil && sectionsEqual(base, theirs):
+		return ours
+	case base == nil && ours == nil:
+		return theirs
+	case base == nil && theirs == nil:
+		return ours
+	}
+
+	label := fmt.Sprintf("the %s section of version %s", ours.nameOr(theirs), version)
+	if name == "" {
+		label = fmt.Sprintf("the introduction of version %s", version)
+	}
+	prose, ok := pick(sectionProse(base), sectionProse(ours), sectionProse(theirs))
+	if !ok {
+		m.conflict("%s was changed on both sides", label)
+		return ours
+	}
+
+	merged := &Section{Heading: ours.headingOr(theirs), Name: ours.nameOr(theirs)}
+	merged.Lines = append(merged.Lines, prose...)
+	entries := m.mergeEntries(label, sectionEntries(base), sectionEntries(ours), sectionEntries(theirs))
+	for _, entry := range entries {
+		merged.Entries = append(merged.Entries, entry)
+		merged.Lines = append(merged.Lines, entry.lines()...)
+	}
+	if len(merged.Entries) == 0 && len(prose) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// headingOr returns the heading of the section, or of the other section
+// if this one is nil.
+func (s *Section) headingOr(other *Section) string {
+	if s == nil {
+		return other.Heading
+	}
+	return s.Heading
+}
+
+// nameOr returns the name of the section, or of the other section if
+// this one is nil.
+func (s *Section) nameOr(other *Section) string {
+	if s == nil {
+		return other.Name
+	}
+	return s.Name
+}
+
+// sectionsEqual returns true if the sections have the same heading and lines.
+func sectionsEqual(a *Section, b *Section) bool {
+	return a.Heading == b.Heading && linesEqual(trimTrailingBlankLines(a.Lines), trimTrailingBlankLines(b.Lines))
+}
+
+// sectionProse returns the lines of the section that are not list items,
+// their details or blank lines.
+func sectionProse(section *Section) []string {
+	if section == nil {
+		return nil
+	}
+	var prose []string
+	inEntry := false
+	for _, line := range section.Lines {
+		trimmed := strings.TrimRight(line, "\r")
+		switch {
+		case strings.TrimSpace(trimmed) == "":
+			continue
+		case entryRegex.MatchString(trimmed):
+			inEntry = true
+		case inEntry && (strings.HasPrefix(trimmed, " ") || strings.HasPrefix(trimmed, "\t")):
+			continue
+		default:
+			inEntry = false
+			prose = append(prose, line)
+		}
+	}
+	return prose
+}
+
+// sectionEntries returns the entries of the section, or nil if there
+// is no section.
+func sectionEntries(section *Section) []*Entry {
+	if section == nil {
+		return nil
+	}
+	return section.Entries
+}
+
+// mergeEntries combines the entries of each side. Entries are matched by
+// their text, ignoring case and a trailing full stop. An entry is removed
+// if either side removed it, and kept if either side added it.
+func (m *changelogMerge) mergeEntries(label string, base []*Entry, ours []*Entry, theirs []*Entry) []*Entry {
+	var merged []*Entry
+	for _, key := range mergeKeys(entryKeys(ours), entryKeys(theirs)) {
+		baseEntry, oursEntry, theirsEntry := findEntry(base, key), findEntry(ours, key), findEntry(theirs, key)
+		switch {
+		case baseEntry != nil && (oursEntry == nil || theirsEntry == nil):
+			logrus.Tracef("dropping entry removed on one side of the merge: %s", key)
+		case oursEntry == nil:
+			merged = append(merged, theirsEntry)
+		case theirsEntry == nil:
+			merged = append(merged, oursEntry)
+		default:
+			lines, ok := pick(entryLines(baseEntry), oursEntry.lines(), theirsEntry.lines())
+			if !ok {
+				m.conflict("the entry '%s' in %s was changed on both sides", oursEntry.Text, label)
+				lines = oursEntry.lines()
+			}
+			if linesEqual(lines, theirsEntry.lines()) {
+				merged = append(merged, theirsEntry)
+			} else {
+				merged = append(merged, oursEntry)
+			}
+		}
+	}
+	return merged
+}
+
+// entryKeys returns the normalised text of the entries.
+func entryKeys(entries []*Entry) []string {
+	var keys []string
+	for _, entry := range entries {
+		keys = append(keys, normaliseEntryText(entry.Text))
+	}
+	return keys
+}
+
+// findEntry returns the first entry with the given normalised text, or nil.
+func findEntry(entries []*Entry, key string) *Entry {
+	for _, entry := range entries {
+		if normaliseEntryText(entry.Text) == key {
+			return entry
+		}
+	}
+	return nil
+}
+
+// entryLines returns the lines of the entry, or no lines if there is
+// no entry.
+func entryLines(entry *Entry) []string {
+	if entry == nil {
+		return nil
+	}
+	return entry.lines()
+}
+
+// hasEntries returns true if any part of the release has entries.
+func hasEntries(release *Release) bool {
+	if intro := introSection(release); intro != nil && len(intro.Entries) > 0 {
+		return true
+	}
+	for _, section := range release.Sections {
+		if len(section.Entries) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// separateSections makes sure that the introduction and each section of
+// a merged release, except the last, end with a blank line.
+func separateSections(release *Release) {
+	if len(release.Intro) > 0 && len(release.Sections) > 0 && !endsWithBlankLine(release.Intro) {
+		release.Intro = append(release.Intro, "")
+	}
+	for i, section := range release.Sections {
+		if i < len(release.Sections)-1 && !endsWithBlankLine(section.Lines) {
+			section.Lines = append(section.Lines, "")
+		}
+	}
+}
+
+// ensureTrailingBlankLine makes sure that the release ends with a blank
+// line, to separate it from the next release.
+func ensureTrailingBlankLine(release *Release) {
+	if endsWithBlankLine(release.Lines()) {
+		return
+	}
+	if len(release.Sections) > 0 {
+		last := release.Sections[len(release.Sections)-1]
+		last.Lines = append(last.Lines, "")
+	} else {
+		release.Intro = append(release.Intro, "")
+	}
+}
+
+func endsWithBlankLine(lines []string) bool {
+	return len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == ""
+}
+
+// mergeFooter merges the link reference definitions of the documents,
+// matching them by label.
+func (m *changelogMerge) mergeFooter(base *Document, ours *Document, theirs *Document) []string {
+	if footer, ok := pick(base.Footer, ours.Footer, theirs.Footer); ok {
+		return footer
+	}
+
+	var footer []string
+	for _, line := range ours.Footer {
+		if strings.TrimSpace(line) != "" {
+			break
+		}
+		footer = append(footer, line)
+	}
+	for _, label := range mergeKeys(linkKeys(ours), linkKeys(theirs)) {
+		baseLink, oursLink, theirsLink := findLinkLine(base, label), findLinkLine(ours, label), findLinkLine(theirs, label)
+		switch {
+		case baseLink != nil && (oursLink == nil || theirsLink == nil):
+			continue
+		case oursLink == nil:
+			footer = append(footer, theirsLink...)
+		case theirsLink == nil:
+			footer = append(footer, oursLink...)
+		default:
+			line, ok := pick(baseLink, oursLink, theirsLink)
+			if !ok {
+				m.conflict("the link for %s was changed on both sides", label)
+			}
+			footer = append(footer, line...)
+		}
+	}
+	return footer
+}
+
+// linkKeys returns the labels of the link reference definitions.
+func linkKeys(doc *Document) []string {
+	var keys []string
+	for _, link := range doc.Links {
+		keys = append(keys, strings.ToLower(link.Label))
+	}
+	return keys
+}
+
+// findLinkLine returns the line of the link reference definition with
+// the given label, or nil if there is none.
+func findLinkLine(doc *Document, label string) []string {
+	for _, line := range doc.Footer {
+		if m := linkReferenceRegex.FindStringSubmatch(strings.TrimRight(line, "\r")); m != nil && strings.ToLower(m[1]) == label {
+			return []string{line}
+		}
+	}
+	return nil
+}
+
+// mergeKeys returns the keys of both sides, in the order of ours, with
+// keys only in theirs inserted before the key that follows them in theirs.
+func mergeKeys(ours []string, theirs []string) []string {
+	merged := append([]string{}, ours...)
+	seen := make(map[string]bool)
+	for _, key := range ours {
+		seen[key] = true
+	}
+	for i, key := range theirs {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		position := len(merged)
+		for _, next := range theirs[i+1:] {
+			if index := indexOf(merged, next); index >= 0 {
+				position = index
+				break
+			}
+		}
+		merged = append(merged[:position], append([]string{key}, merged[position:]...)...)
+	}
+	return merged
+}
+
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func linesEqual(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
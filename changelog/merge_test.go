@@ -0,0 +1,282 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const mergeBase = `# Changelog
+
+## [Unreleased]
+### Added
+- feat: one
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`
+
+func TestMergeChangelogs(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts []string
+	}{
+		{
+			name: "entries added on both sides",
+			base: mergeBase,
+			ours: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: one
+- feat: two
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+			theirs: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: one
+- feat: three
+
+### Fixed
+- fix: a bug
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+			want: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: one
+- feat: two
+- feat: three
+
+### Fixed
+- fix: a bug
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+		},
+		{
+			name: "unreleased entries released on one side",
+			base: mergeBase,
+			ours: `# Changelog
+
+## [1.1.0] - 2024-02-01
+### Added
+- feat: one
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[1.1.0]: https://example.com/releases/1.1.0
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+			theirs: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: one
+
+### Fixed
+- fix: a bug
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+			want: `# Changelog
+
+## [Unreleased]
+### Fixed
+- fix: a bug
+
+## [1.1.0] - 2024-02-01
+### Added
+- feat: one
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[1.1.0]: https://example.com/releases/1.1.0
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+		},
+		{
+			name: "entry removed on one side and added on the other",
+			base: mergeBase,
+			ours: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: two
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+			theirs: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: one
+- feat: three
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+			want: `# Changelog
+
+## [Unreleased]
+### Added
+- feat: two
+- feat: three
+
+## [1.0.0] - 2024-01-01
+### Added
+- feat: initial
+
+[Unreleased]: https://example.com/compare/1.0.0...HEAD
+[1.0.0]: https://example.com/releases/1.0.0
+`,
+		},
+		{
+			name:   "entries without sections",
+			base:   "# Changelog\n\n## Unreleased\n- one\n",
+			ours:   "# Changelog\n\n## Unreleased\n- one\n- two\n",
+			theirs: "# Changelog\n\n## Unreleased\n- one\n- three\n  with details\n",
+			want:   "# Changelog\n\n## Unreleased\n- one\n- two\n- three\n  with details\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   mergeBase,
+			ours:   "# Changelog\n\n## [Unreleased]\n### Added\n- feat: one\n- feat: two\n",
+			theirs: "# Changelog\n\n## [Unreleased]\n### Added\n- feat: one\n- feat: two\n",
+			want:   "# Changelog\n\n## [Unreleased]\n### Added\n- feat: one\n- feat: two\n",
+		},
+		{
+			name:   "links added on both sides",
+			base:   "# Changelog\n\n## [1.0.0]\n- initial\n\n[1.0.0]: https://example.com/1.0.0\n",
+			ours:   "# Changelog\n\n## [1.1.0]\n- ours\n\n## [1.0.0]\n- initial\n\n[1.1.0]: https://example.com/1.1.0\n[1.0.0]: https://example.com/1.0.0\n",
+			theirs: "# Changelog\n\n## [1.0.0]\n- initial\n\n## [0.9.0]\n- theirs\n\n[1.0.0]: https://example.com/1.0.0\n[0.9.0]: https://example.com/0.9.0\n",
+			want:   "# Changelog\n\n## [1.1.0]\n- ours\n\n## [1.0.0]\n- initial\n\n## [0.9.0]\n- theirs\n\n[1.1.0]: https://example.com/1.1.0\n[1.0.0]: https://example.com/1.0.0\n[0.9.0]: https://example.com/0.9.0\n",
+		},
+		{
+			name:          "same version with different dates",
+			base:          "# Changelog\n",
+			ours:          "# Changelog\n\n## [1.1.0] - 2024-02-01\n### Added\n- feat: one\n",
+			theirs:        "# Changelog\n\n## [1.1.0] - 2024-02-02\n### Added\n- feat: one\n",
+			wantConflicts: []string{"version 1.1.0 has different dates: 2024-02-01 and 2024-02-02"},
+		},
+		{
+			name:          "entry details changed on both sides",
+			base:          "# Changelog\n\n## Unreleased\n- one\n  base\n",
+			ours:          "# Changelog\n\n## Unreleased\n- one\n  ours\n",
+			theirs:        "# Changelog\n\n## Unreleased\n- one\n  theirs\n",
+			wantConflicts: []string{"the entry 'one' in the introduction of version Unreleased was changed on both sides"},
+		},
+		{
+			name:          "preamble changed on both sides",
+			base:          mergeBase,
+			ours:          "# Changes\n\n## [Unreleased]\n### Added\n- feat: one\n",
+			theirs:        "# History\n\n## [Unreleased]\n### Added\n- feat: one\n",
+			wantConflicts: []string{"the preamble was changed on both sides"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeChangelogs(tt.base, tt.ours, tt.theirs)
+			if tt.wantConflicts != nil {
+				var conflictErr *MergeConflictError
+				if !errors.As(err, &conflictErr) {
+					t.Fatalf("MergeChangelogs() error = %v, want conflicts", err)
+				}
+				if !reflect.DeepEqual(conflictErr.Conflicts, tt.wantConflicts) {
+					t.Errorf("MergeChangelogs() conflicts = %v, want %v", conflictErr.Conflicts, tt.wantConflicts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeChangelogs() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MergeChangelogs() got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mergeKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		ours   []string
+		theirs []string
+		want   []string
+	}{
+		{name: "same keys", ours: []string{"a", "b"}, theirs: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "new key first", ours: []string{"b", "c"}, theirs: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "new key between", ours: []string{"a", "c"}, theirs: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "new key last", ours: []string{"a"}, theirs: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "keys on each side", ours: []string{"a", "x"}, theirs: []string{"y", "a"}, want: []string{"y", "a", "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeKeys(tt.ours, tt.theirs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"golang.org/x/exp/slices"
+	"strings"
+)
+
+// EntryOrder is the order of the entries within each section of a release.
+type EntryOrder string
+
+const (
+	// EntryOrderAlphabetical sorts entries by their text, as rendered
+	// after applying the entry options and any Changelog trailer.
+	EntryOrderAlphabetical EntryOrder = "alphabetical"
+
+	// EntryOrderChronological lists entries in the order they were
+	// committed, oldest first.
+	EntryOrderChronological EntryOrder = "chronological"
+
+	// EntryOrderReverseChronological lists entries newest first.
+	EntryOrderReverseChronological EntryOrder = "reverse-chronological"
+
+	// EntryOrderScope groups entries by scope, sorted by scope name, with
+	// unscoped entries last. Entries with the same scope are sorted by
+	// their text.
+	EntryOrderScope EntryOrder = "scope"
+)
+
+// EntryOrders lists the supported entry orders.
+var EntryOrders = []EntryOrder{EntryOrderAlphabetical, EntryOrderChronological, EntryOrderReverseChronological, EntryOrderScope}
+
+// ParseEntryOrder returns the entry order with the given name. An empty
+// name is the default, alphabetical order.
+func ParseEntryOrder(name string) (EntryOrder, error) {
+	if name == "" {
+		return EntryOrderAlphabetical, nil
+	}
+	for _, order := range EntryOrders {
+		if strings.EqualFold(name, string(order)) {
+			return order, nil
+		}
+	}
+	var names []string
+	for _, order := range EntryOrders {
+		names = append(names, string(order))
+	}
+	return "", fmt.Errorf("unsupported entry order: %s, must be one of: %s", name, strings.Join(names, ", "))
+}
+
+// sortCommits sorts the commits of a section, which are in the order
+// of the history walk, newest first, into the entry order.
+func sortCommits(commits []CommitData, order EntryOrder) {
+	switch order {
+	case EntryOrderChronological:
+		for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+			commits[i], commits[j] = commits[j], commits[i]
+		}
+		slices.SortStableFunc(commits, func(a, b CommitData) bool {
+			return a.Committed.Before(b.Committed)
+		})
+
+	case EntryOrderReverseChronological:
+		slices.SortStableFunc(commits, func(a, b CommitData) bool {
+			return a.Committed.After(b.Committed)
+		})
+
+	case EntryOrderScope:
+		slices.SortStableFunc(commits, func(a, b CommitData) bool {
+			if (a.Scope == "") != (b.Scope == "") {
+				return b.Scope == ""
+			}
+			if a.Scope != b.Scope {
+				return a.Scope < b.Scope
+			}
+			return a.Text < b.Text
+		})
+
+	default:
+		slices.SortStableFunc(commits, func(a, b CommitData) bool {
+			return a.Text < b.Text
+		})
+	}
+}
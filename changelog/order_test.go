@@ -0,0 +1,146 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseEntryOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    EntryOrder
+		wantErr bool
+	}{
+		{name: "", want: EntryOrderAlphabetical},
+		{name: "Chronological", want: EntryOrderChronological},
+		{name: "reverse-chronological", want: EntryOrderReverseChronological},
+		{name: "scope", want: EntryOrderScope},
+		{name: "random", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEntryOrder(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEntryOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEntryOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sortCommits(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+	// commits in the order of the history walk, newest first
+	walked := []vcs.CommitDetail{
+		{Message: "feat(ui): add dark mode", CommitDate: at(4)},
+		{Message: "feat: add search", CommitDate: at(3)},
+		{Message: "feat(api): add pagination", CommitDate: at(1)},
+		{Message: "feat(api): add filtering", CommitDate: at(2)},
+	}
+
+	tests := []struct {
+		order EntryOrder
+		want  []string
+	}{
+		{
+			order: EntryOrderAlphabetical,
+			want:  []string{"feat(api): add filtering", "feat(api): add pagination", "feat(ui): add dark mode", "feat: add search"},
+		},
+		{
+			order: EntryOrderChronological,
+			want:  []string{"feat(api): add pagination", "feat(api): add filtering", "feat: add search", "feat(ui): add dark mode"},
+		},
+		{
+			order: EntryOrderReverseChronological,
+			want:  []string{"feat(ui): add dark mode", "feat: add search", "feat(api): add filtering", "feat(api): add pagination"},
+		},
+		{
+			order: EntryOrderScope,
+			want:  []string{"feat(api): add filtering", "feat(api): add pagination", "feat(ui): add dark mode", "feat: add search"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			var commits []CommitData
+			for _, detail := range walked {
+				commit := buildCommitData(detail)
+				commit.Text = commit.Message
+				commits = append(commits, commit)
+			}
+			sortCommits(commits, tt.order)
+
+			var got []string
+			for _, commit := range commits {
+				got = append(got, commit.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortCommits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sortCommits_byText(t *testing.T) {
+	tests := []struct {
+		order EntryOrder
+		want  []string
+	}{
+		{order: EntryOrderAlphabetical, want: []string{"Fix CVE-2023-1234", "Zebra mode"}},
+		{order: EntryOrderScope, want: []string{"Fix CVE-2023-1234", "Zebra mode"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			// the text, not the commit message, determines the order
+			commits := []CommitData{
+				{Message: "feat(api): add zebra mode", Scope: "api", Text: "Zebra mode"},
+				{Message: "fix(api): bump parser", Scope: "api", Text: "Fix CVE-2023-1234"},
+			}
+			sortCommits(commits, tt.order)
+
+			var got []string
+			for _, commit := range commits {
+				got = append(got, commit.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortCommits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sortCommits_chronologicalWithoutDates(t *testing.T) {
+	commits := []CommitData{{Message: "b: newest"}, {Message: "a: oldest"}}
+	sortCommits(commits, EntryOrderChronological)
+	if commits[0].Message != "a: oldest" || commits[1].Message != "b: newest" {
+		t.Errorf("sortCommits() = %v, want the reverse of the walk order", commits)
+	}
+}
+
+func TestNewRenderer_invalidEntryOrder(t *testing.T) {
+	if _, err := NewRenderer(cfg.SinceConfig{Changelog: cfg.ChangelogConfig{EntryOrder: "random"}}, "", FormatMarkdown); err == nil {
+		t.Error("NewRenderer() expected error for an unsupported entry order")
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/rogpeppe/go-internal/semver"
+	"strings"
+)
+
+// VersionRange selects the releases between two versions. An empty From
+// or To leaves that end of the range open. Both ends are inclusive unless
+// the corresponding Exclusive flag is set.
+type VersionRange struct {
+	From          string
+	To            string
+	FromExclusive bool
+	ToExclusive   bool
+}
+
+// String returns a description of the range for use in messages.
+func (r VersionRange) String() string {
+	from := r.From
+	if from == "" {
+		from = "the earliest version"
+	}
+	to := r.To
+	if to == "" {
+		to = "the latest version"
+	}
+	return from + " and " + to
+}
+
+// contains returns true if the version is within the range.
+// Both the version and the ends of the range must be valid semver.
+func (r VersionRange) contains(version string) bool {
+	v := canonicalVersion(version)
+	if r.From != "" {
+		c := semver.Compare(v, canonicalVersion(r.From))
+		if c < 0 || (c == 0 && r.FromExclusive) {
+			return false
+		}
+	}
+	if r.To != "" {
+		c := semver.Compare(v, canonicalVersion(r.To))
+		if c > 0 || (c == 0 && r.ToExclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReleasesInRange returns the releases whose versions fall within the range,
+// in the order they appear in the document. The Unreleased section, and
+// releases without a valid semantic version, are never included.
+func (d *Document) ReleasesInRange(versionRange VersionRange) ([]*Release, error) {
+	for _, v := range []string{versionRange.From, versionRange.To} {
+		if v != "" && !semver.IsValid(canonicalVersion(v)) {
+			return nil, fmt.Errorf("invalid version in range: %s", v)
+		}
+	}
+
+	var releases []*Release
+	for _, release := range d.Releases {
+		if !semver.IsValid(canonicalVersion(release.Version)) {
+			continue
+		}
+		if versionRange.contains(release.Version) {
+			releases = append(releases, release)
+		}
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("could not find any versions between %s in changelog", versionRange)
+	}
+	return releases, nil
+}
+
+// MergeSections combines the same-named sections of the given releases,
+// so that, for example, all 'Fixed' entries are listed together. Sections
+// are ordered by their first appearance, and entries keep the order of
+// the releases. Entries that are not under a section heading are combined
+// into a section without a heading.
+func MergeSections(releases []*Release) []*Section {
+	var merged []*Section
+	byName := make(map[string]*Section)
+	for _, release := range releases {
+		sections := release.Sections
+		if intro := introSection(release); intro != nil && len(intro.Entries) > 0 {
+			sections = append([]*Section{intro}, sections...)
+		}
+		for _, section := range sections {
+			existing := byName[section.Name]
+			if existing == nil {
+				existing = &Section{Name: section.Name}
+				byName[section.Name] = existing
+				merged = append(merged, existing)
+			}
+			existing.Entries = append(existing.Entries, section.Entries...)
+		}
+	}
+	for _, section := range merged {
+		section.render()
+	}
+	return merged
+}
+
+// ParseChangelogRange loads a changelog file at the given path, in the given format, and returns the lines of all
+// releases within the range. If merge is true, the same-named sections of the releases are
+// combined, and no version headers are included.
+func ParseChangelogRange(path string, format Format, versionRange VersionRange, includeHeader bool, merge bool) ([]string, error) {
+	doc, err := ReadDocument(path, format)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := doc.ReleasesInRange(versionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	if merge {
+		for i, section := range MergeSections(releases) {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			if section.Heading != "" {
+				lines = append(lines, section.Heading)
+			}
+			lines = append(lines, trimTrailingBlankLines(section.Lines)...)
+		}
+		return lines, nil
+	}
+
+	for i, release := range releases {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		changes := release.Lines()
+		if !includeHeader {
+			changes = changes[1:]
+		}
+		lines = append(lines, trimTrailingBlankLines(changes)...)
+	}
+	return lines, nil
+}
+
+// canonicalVersion returns the version with a 'v' prefix, as required
+// by the semver package.
+func canonicalVersion(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReleasesInRange(t *testing.T) {
+	doc := ParseDocument(strings.Split(`## [Unreleased]
+## [v2.0.0] - 2024-03-01
+## [1.1.0] - 2024-02-01
+## [nightly]
+## [1.0.0] - 2024-01-01
+`, "\n"))
+
+	releases, err := doc.ReleasesInRange(VersionRange{From: "v1.0.0", FromExclusive: true})
+	if err != nil {
+		t.Fatalf("ReleasesInRange() error = %v", err)
+	}
+	var got []string
+	for _, release := range releases {
+		got = append(got, release.Version)
+	}
+	if want := []string{"v2.0.0", "1.1.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReleasesInRange() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeSections(t *testing.T) {
+	doc := ParseDocument(strings.Split(`## [1.1.0]
+### Fixed
+- fix: b
+  details of b
+
+## [1.0.0]
+### Added
+- feat: a
+
+### Fixed
+- fix: a
+`, "\n"))
+
+	merged := MergeSections(doc.Releases)
+	var got []string
+	for _, section := range merged {
+		got = append(got, section.Heading)
+		got = append(got, section.Lines...)
+	}
+	want := []string{"### Fixed", "- fix: b", "  details of b", "- fix: a", "### Added", "- feat: a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeSections() = %q, want %q", got, want)
+	}
+}
+
+func TestMergeSections_entriesWithoutSections(t *testing.T) {
+	doc := ParseDocument(strings.Split(`## [1.2.0]
+- fix: c
+
+## [1.1.0]
+### Fixed
+- fix: b
+
+## [1.0.0]
+- feat: a
+  details of a
+`, "\n"))
+
+	merged := MergeSections(doc.Releases)
+	var got []string
+	for _, section := range merged {
+		got = append(got, section.Heading)
+		got = append(got, section.Lines...)
+	}
+	want := []string{"", "- fix: c", "- feat: a", "  details of a", "### Fixed", "- fix: b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeSections() = %q, want %q", got, want)
+	}
+}
@@ -34,12 +34,12 @@ func ResolveChangelogFile(dir string, fileName string) string {
 
 // ParseChangelog loads a changelog file at the given path and returns a slice of strings containing changelog entries
 // from the specified version. If no version is specified, the most recent is used.
-func ParseChangelog(path string, version string, includeHeader bool) ([]string, error) {
-	lines, err := ReadFile(path)
+func ParseChangelog(path string, format Format, version string, includeHeader bool) ([]string, error) {
+	doc, err := ReadDocument(path, format)
 	if err != nil {
 		return nil, err
 	}
-	return readChanges(lines, version, includeHeader)
+	return releaseChanges(doc, version, includeHeader)
 }
 
 // ReadFile loads a changelog file at the given path and returns a slice of strings containing each line.
@@ -60,36 +60,41 @@ func ReadFile(path string) ([]string, error) {
 	return lines, nil
 }
 
-// readChanges parses a changelog and returns all content starting with the h2 for the specified version,
-// before the next h2, or the end of the file. If no version is specified, the first h2 is used.
-func readChanges(lines []string, version string, includeHeader bool) ([]string, error) {
-	// find the first h2
-	firstH2 := 0
-	for i, line := range lines {
-		if strings.HasPrefix(line, "## ") {
-			if len(version) == 0 || strings.Contains(line, "["+version+"]") {
-				if includeHeader {
-					firstH2 = i
-				} else {
-					firstH2 = i + 1
-				}
-				break
-			}
-		}
+// ReadDocument loads a changelog file at the given path and parses it into a Document,
+// in the given format, such as one returned by ResolveFormat.
+func ReadDocument(path string, format Format) (*Document, error) {
+	lines, err := ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-	if firstH2 == 0 {
-		if version == "" {
+	return ParseDocumentAs(lines, format), nil
+}
+
+// readChanges parses a changelog and returns the content of the release section for the specified version,
+// up to the next release, or the end of the file. If no version is specified, the first release section is used.
+func readChanges(lines []string, version string, includeHeader bool) ([]string, error) {
+	return releaseChanges(ParseDocument(lines), version, includeHeader)
+}
+
+// releaseChanges returns the content of the release section of the document for the specified version.
+// If no version is specified, the first release section is used.
+func releaseChanges(doc *Document, version string, includeHeader bool) ([]string, error) {
+	var release *Release
+	if version == "" {
+		if len(doc.Releases) == 0 {
 			return nil, fmt.Errorf("changelog contains no version sections")
 		}
-		return nil, fmt.Errorf("could not find version %s in changelog", version)
-	}
-	// find the next h2, or the end of the file
-	nextH2 := len(lines) - firstH2 - 1
-	for i, line := range lines[firstH2+1:] {
-		if strings.HasPrefix(line, "## ") {
-			nextH2 = i
-			break
+		release = doc.Releases[0]
+	} else {
+		release = doc.FindRelease(version)
+		if release == nil {
+			return nil, fmt.Errorf("could not find version %s in changelog", version)
 		}
 	}
-	return lines[firstH2 : firstH2+nextH2], nil
+
+	changes := release.Lines()
+	if !includeHeader {
+		changes = changes[1:]
+	}
+	return trimTrailingBlankLines(changes), nil
 }
@@ -79,7 +79,7 @@ func TestParseChangelog(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	got, err := ParseChangelog(filePath, "1.0.0", false)
+	got, err := ParseChangelog(filePath, FormatMarkdown, "1.0.0", false)
 	if err != nil {
 		t.Fatalf("ParseChangelog() error = %v", err)
 	}
@@ -107,7 +107,7 @@ func TestParseChangelog_withHeader(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	got, err := ParseChangelog(filePath, "1.0.0", true)
+	got, err := ParseChangelog(filePath, FormatMarkdown, "1.0.0", true)
 	if err != nil {
 		t.Fatalf("ParseChangelog() error = %v", err)
 	}
@@ -120,7 +120,7 @@ func TestParseChangelog_withHeader(t *testing.T) {
 }
 
 func TestParseChangelog_nonExistent(t *testing.T) {
-	_, err := ParseChangelog("/nonexistent/CHANGELOG.md", "1.0.0", false)
+	_, err := ParseChangelog("/nonexistent/CHANGELOG.md", FormatMarkdown, "1.0.0", false)
 	if err == nil {
 		t.Error("ParseChangelog() expected error for non-existent file")
 	}
@@ -204,3 +204,41 @@ func Test_readChanges_firstVersion(t *testing.T) {
 		t.Errorf("readChanges() = %v, want %v", got, want)
 	}
 }
+
+func Test_readChanges_ignoresHeadingsInCodeBlocks(t *testing.T) {
+	lines := []string{
+		"# Changelog",
+		"",
+		"## [1.0.0] - 2024-01-01",
+		"### Changed",
+		"- docs: example",
+		"",
+		"```markdown",
+		"## [0.9.0] - not a release",
+		"```",
+		"",
+		"## [0.9.0] - 2023-12-01",
+		"- fix: bar",
+		"",
+		"[1.0.0]: https://example.com/1.0.0",
+		"[0.9.0]: https://example.com/0.9.0",
+		"",
+	}
+	got, err := readChanges(lines, "1.0.0", false)
+	if err != nil {
+		t.Fatalf("readChanges() error = %v", err)
+	}
+	want := []string{"### Changed", "- docs: example", "", "```markdown", "## [0.9.0] - not a release", "```"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readChanges() = %v, want %v", got, want)
+	}
+
+	// link reference definitions should not be part of the last release
+	got, err = readChanges(lines, "0.9.0", false)
+	if err != nil {
+		t.Fatalf("readChanges() error = %v", err)
+	}
+	if want := []string{"- fix: bar"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readChanges() = %v, want %v", got, want)
+	}
+}
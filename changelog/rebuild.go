@@ -0,0 +1,155 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"github.com/rogpeppe/go-internal/semver"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+	"os"
+	"strings"
+)
+
+// RebuildChangelog regenerates the release sections of the changelog from
+// the tags in the repository, returning the full changelog.
+//
+// Only the releases within the range are regenerated; other releases, the
+// Unreleased section, the preamble and any link reference definitions are
+// kept from the existing changelog. If keepExisting is true, releases that
+// are already in the changelog are kept as they are, and only missing
+// releases are generated. If the changelog file does not exist, it is
+// created with the boilerplate for its format.
+func RebuildChangelog(
+	config cfg.SinceConfig,
+	commitCfg vcs.CommitConfig,
+	changelogFile string,
+	repoPath string,
+	versionRange VersionRange,
+	keepExisting bool,
+) (string, error) {
+	for _, v := range []string{versionRange.From, versionRange.To} {
+		if v != "" && !semver.IsValid(canonicalVersion(v)) {
+			return "", fmt.Errorf("invalid version in range: %s", v)
+		}
+	}
+
+	format, err := ResolveFormat(config, changelogFile)
+	if err != nil {
+		return "", err
+	}
+	lines, err := ReadFile(changelogFile)
+	if os.IsNotExist(err) {
+		logrus.Debugf("changelog file %s does not exist, creating it", changelogFile)
+		lines = strings.Split(changelogBoilerplate(format), "\n")
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read changelog file: %s: %v", changelogFile, err)
+	}
+	existing := ParseDocumentAs(lines, format)
+
+	commits, _, err := vcs.FetchCommitsByTag(config, commitCfg, repoPath, "", "")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch commit messages from repo: %s: %v", repoPath, err)
+	}
+	renderer, err := NewRenderer(config, repoPath, format)
+	if err != nil {
+		return "", err
+	}
+
+	releases := existing.Releases
+	var rebuilt int
+	for _, tagCommits := range *commits {
+		if tagCommits.Name == vcs.UnreleasedVersionName {
+			continue
+		}
+		inRange := versionRange == VersionRange{} || (semver.IsValid(canonicalVersion(tagCommits.Name)) && versionRange.contains(tagCommits.Name))
+		if !inRange {
+			continue
+		}
+		current := existing.FindRelease(tagCommits.Name)
+		if current != nil && keepExisting {
+			logrus.Debugf("keeping existing section for version %s", current.Version)
+			continue
+		}
+
+		rendered, err := renderer.Render(&[]vcs.TagCommits{tagCommits}, true, false, vcs.UnreleasedVersionName)
+		if err != nil {
+			return "", err
+		}
+		parsed := ParseDocumentAs(strings.Split(rendered, "\n"), format)
+		if len(parsed.Releases) == 0 {
+			return "", fmt.Errorf("failed to parse rendered section for version %s", tagCommits.Name)
+		}
+		if current != nil {
+			releases = replaceRelease(releases, current, parsed.Releases[0])
+		} else {
+			releases = append(releases, parsed.Releases[0])
+		}
+		rebuilt++
+	}
+	logrus.Debugf("rebuilt %d release sections", rebuilt)
+
+	sortReleases(releases)
+	return renderRebuilt(existing, releases), nil
+}
+
+// replaceRelease returns the releases with the old release replaced by the new one.
+func replaceRelease(releases []*Release, old *Release, new *Release) []*Release {
+	replaced := make([]*Release, len(releases))
+	for i, release := range releases {
+		if release == old {
+			replaced[i] = new
+		} else {
+			replaced[i] = release
+		}
+	}
+	return replaced
+}
+
+// sortReleases sorts the releases by descending version, keeping the
+// Unreleased section first. Releases without a valid semantic version
+// are placed last, in their existing order.
+func sortReleases(releases []*Release) {
+	slices.SortStableFunc(releases, func(a, b *Release) bool {
+		if a.IsUnreleased() || b.IsUnreleased() {
+			return a.IsUnreleased() && !b.IsUnreleased()
+		}
+		return semver.Compare(canonicalVersion(a.Version), canonicalVersion(b.Version)) > 0
+	})
+}
+
+// renderRebuilt renders the preamble and footer of the existing document
+// around the releases, separating each part with a blank line.
+func renderRebuilt(existing *Document, releases []*Release) string {
+	var parts []string
+	if preamble := trimTrailingBlankLines(existing.Preamble); len(preamble) > 0 {
+		parts = append(parts, strings.Join(preamble, "\n"))
+	}
+	for _, release := range releases {
+		parts = append(parts, strings.Join(trimTrailingBlankLines(release.Lines()), "\n"))
+	}
+	footer := trimTrailingBlankLines(existing.Footer)
+	for len(footer) > 0 && strings.TrimSpace(footer[0]) == "" {
+		footer = footer[1:]
+	}
+	if len(footer) > 0 {
+		parts = append(parts, strings.Join(footer, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
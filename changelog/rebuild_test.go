@@ -0,0 +1,163 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestRebuildChangelog(t *testing.T) {
+	repoDir := createTestRepo(t)
+	today := time.Now().Format("2006-01-02")
+
+	existing := `# Changelog
+
+Hand-written introduction.
+
+## [Unreleased]
+### Added
+- Something in progress
+
+## [0.1.0] - 2020-01-02
+### Added
+- A hand-edited entry
+
+## [0.0.1] - 2020-01-01
+### Added
+- Another hand-edited entry
+
+[0.1.0]: https://example.com/0.1.0
+`
+
+	tests := []struct {
+		name         string
+		existing     string
+		versionRange VersionRange
+		keepExisting bool
+		want         string
+		wantErr      bool
+	}{
+		{
+			name: "new changelog",
+			want: fmt.Sprintf(`# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [0.1.0] - %[1]v
+### Added
+- feat: second update
+
+## [0.0.1] - %[1]v
+### Added
+- feat: first update`, today),
+		},
+		{
+			name:     "rebuild all versions",
+			existing: existing,
+			want: fmt.Sprintf(`# Changelog
+
+Hand-written introduction.
+
+## [Unreleased]
+### Added
+- Something in progress
+
+## [0.1.0] - %[1]v
+### Added
+- feat: second update
+
+## [0.0.1] - %[1]v
+### Added
+- feat: first update
+
+[0.1.0]: https://example.com/0.1.0`, today),
+		},
+		{
+			name:         "rebuild range",
+			existing:     existing,
+			versionRange: VersionRange{To: "0.0.1"},
+			want: fmt.Sprintf(`# Changelog
+
+Hand-written introduction.
+
+## [Unreleased]
+### Added
+- Something in progress
+
+## [0.1.0] - 2020-01-02
+### Added
+- A hand-edited entry
+
+## [0.0.1] - %[1]v
+### Added
+- feat: first update
+
+[0.1.0]: https://example.com/0.1.0`, today),
+		},
+		{
+			name: "keep existing versions",
+			existing: `# Changelog
+
+## [0.1.0] - 2020-01-02
+### Added
+- A hand-edited entry
+`,
+			keepExisting: true,
+			want: fmt.Sprintf(`# Changelog
+
+## [0.1.0] - 2020-01-02
+### Added
+- A hand-edited entry
+
+## [0.0.1] - %[1]v
+### Added
+- feat: first update`, today),
+		},
+		{
+			name:         "invalid range",
+			versionRange: VersionRange{From: "latest"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changelogFile := path.Join(t.TempDir(), "CHANGELOG.md")
+			if tt.existing != "" {
+				if err := os.WriteFile(changelogFile, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := RebuildChangelog(cfg.SinceConfig{}, vcs.CommitConfig{}, changelogFile, repoDir, tt.versionRange, tt.keepExisting)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RebuildChangelog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RebuildChangelog() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
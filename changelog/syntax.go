@@ -0,0 +1,281 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// syntax describes how a changelog format marks up its releases,
+// sections and entries.
+type syntax interface {
+	// parseRelease returns the release introduced by the line at index i,
+	// or nil if the line is not a release heading.
+	parseRelease(lines []string, i int) *Release
+
+	// parseSection returns the name of the section introduced by the line
+	// at index i, if the line is a section heading.
+	parseSection(lines []string, i int) (string, bool)
+
+	// parseEntry returns the text of the list item on the line, if any.
+	parseEntry(line string) (string, bool)
+
+	// parseDate returns the release date from a trailer line, for formats
+	// that date a release after its entries.
+	parseDate(line string) (string, bool)
+}
+
+var (
+	listItemRegex      = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	asciiDocEntryRegex = regexp.MustCompile(`^\*+\s+(.*)$`)
+	debianHeadingRegex = regexp.MustCompile(`^(\S+) \(([^)]+)\) ([^;]*);.*$`)
+	debianSectionRegex = regexp.MustCompile(`^  \[\s*(.+?)\s*\]\s*$`)
+	debianEntryRegex   = regexp.MustCompile(`^  [*+-]\s+(.*)$`)
+	debianTrailerRegex = regexp.MustCompile(`^ -- .*>  (.+)$`)
+	rpmHeadingRegex    = regexp.MustCompile(`^\* (\w{3} \w{3} +\d{1,2} \d{4}) .*? - (\S+)\s*$`)
+
+	// specSectionRegex matches the directives that start a section of an
+	// RPM spec file, such as '%files'.
+	specSectionRegex = regexp.MustCompile(`^%(package|description|prep|build|install|check|clean|files|pre|post|preun|postun|pretrans|posttrans|triggerin|triggerun|triggerpostun|verifyscript|changelog)\b`)
+)
+
+// parseWithSyntax parses the lines of a changelog file into a Document,
+// using the given syntax. Entries that are not under a section heading
+// are added to an implicit section, without a heading.
+func parseWithSyntax(lines []string, s syntax) *Document {
+	doc := &Document{}
+
+	var release *Release
+	var section *Section
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		if r := s.parseRelease(lines, i); r != nil {
+			release = r
+			release.Line = i + 1
+			section = nil
+			doc.Releases = append(doc.Releases, release)
+			continue
+		}
+		if release == nil {
+			doc.Preamble = append(doc.Preamble, line)
+			continue
+		}
+		if name, ok := s.parseSection(lines, i); ok {
+			section = &Section{Heading: line, Name: name, Line: i + 1}
+			release.Sections = append(release.Sections, section)
+			continue
+		}
+		if date, ok := s.parseDate(trimmed); ok {
+			release.Date = date
+		} else if text, ok := s.parseEntry(trimmed); ok {
+			if section == nil {
+				section = &Section{Line: i + 1}
+				release.Sections = append(release.Sections, section)
+			}
+			section.Entries = append(section.Entries, &Entry{Text: strings.TrimSpace(text)})
+		} else if section != nil && len(section.Entries) > 0 && strings.TrimSpace(trimmed) != "" && strings.TrimLeft(trimmed, " \t") != trimmed {
+			last := section.Entries[len(section.Entries)-1]
+			last.Details = append(last.Details, strings.TrimSpace(trimmed))
+		}
+
+		if section != nil {
+			section.Lines = append(section.Lines, line)
+		} else {
+			release.Intro = append(release.Intro, line)
+		}
+	}
+	return doc
+}
+
+// asciiDocSyntax reads AsciiDoc changelogs, with '==' release headings,
+// '===' section headings and '*' list items.
+type asciiDocSyntax struct{}
+
+func (asciiDocSyntax) parseRelease(lines []string, i int) *Release {
+	line := strings.TrimRight(lines[i], "\r")
+	if !strings.HasPrefix(line, "== ") {
+		return nil
+	}
+	return parseVersionHeading(lines[i], strings.TrimPrefix(line, "=="))
+}
+
+func (asciiDocSyntax) parseSection(lines []string, i int) (string, bool) {
+	line := strings.TrimRight(lines[i], "\r")
+	if !strings.HasPrefix(line, "=== ") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, "===")), true
+}
+
+func (asciiDocSyntax) parseEntry(line string) (string, bool) {
+	if m := asciiDocEntryRegex.FindStringSubmatch(line); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func (asciiDocSyntax) parseDate(string) (string, bool) {
+	return "", false
+}
+
+// rstSyntax reads reStructuredText changelogs, with release titles
+// underlined with '-', section titles underlined with '~' and '-' list items.
+type rstSyntax struct{}
+
+func (rstSyntax) parseRelease(lines []string, i int) *Release {
+	if title, ok := rstTitle(lines, i, '-'); ok {
+		return parseVersionHeading(lines[i], title)
+	}
+	return nil
+}
+
+func (rstSyntax) parseSection(lines []string, i int) (string, bool) {
+	return rstTitle(lines, i, '~')
+}
+
+func (rstSyntax) parseEntry(line string) (string, bool) {
+	if m := listItemRegex.FindStringSubmatch(line); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func (rstSyntax) parseDate(string) (string, bool) {
+	return "", false
+}
+
+// rstTitle returns the title on the line at index i, if the next line
+// underlines it with the given character.
+func rstTitle(lines []string, i int, underline rune) (string, bool) {
+	if i+1 >= len(lines) {
+		return "", false
+	}
+	title := strings.TrimSpace(strings.TrimRight(lines[i], "\r"))
+	next := strings.TrimRight(lines[i+1], "\r")
+	if title == "" || len(next) < len(title) || strings.Trim(next, string(underline)) != "" {
+		return "", false
+	}
+	return title, true
+}
+
+// debianSyntax reads Debian changelogs, with 'package (version)
+// distribution; urgency=...' headings, optional '[ Section ]' headings,
+// '*' list items and a trailer line holding the maintainer and date.
+type debianSyntax struct{}
+
+func (debianSyntax) parseRelease(lines []string, i int) *Release {
+	m := debianHeadingRegex.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+	if m == nil {
+		return nil
+	}
+	return &Release{Heading: lines[i], Version: m[2]}
+}
+
+func (debianSyntax) parseSection(lines []string, i int) (string, bool) {
+	if m := debianSectionRegex.FindStringSubmatch(strings.TrimRight(lines[i], "\r")); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func (debianSyntax) parseEntry(line string) (string, bool) {
+	if m := debianEntryRegex.FindStringSubmatch(line); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func (debianSyntax) parseDate(line string) (string, bool) {
+	m := debianTrailerRegex.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	date, err := time.Parse(time.RFC1123Z, strings.TrimSpace(m[1]))
+	if err != nil {
+		return "", true
+	}
+	return date.Format("2006-01-02"), true
+}
+
+// parseRPMChangelog parses an RPM changelog. In a spec file, only the lines
+// between the '%changelog' directive and the next section, if any, are the
+// changelog: the lines up to and including the directive are the preamble,
+// and the following sections are the footer.
+func parseRPMChangelog(lines []string) *Document {
+	start := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "%changelog" {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return parseWithSyntax(lines, rpmSyntax{})
+	}
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if specSectionRegex.MatchString(lines[i]) {
+			end = i
+			break
+		}
+	}
+
+	doc := parseWithSyntax(lines[start+1:end], rpmSyntax{})
+	doc.Preamble = append(append([]string{}, lines[:start+1]...), doc.Preamble...)
+	doc.Footer = append(doc.Footer, lines[end:]...)
+	for _, release := range doc.Releases {
+		release.Line += start + 1
+		for _, section := range release.Sections {
+			section.Line += start + 1
+		}
+	}
+	return doc
+}
+
+// rpmSyntax reads RPM changelogs, with '* Day Mon DD YYYY name <email> -
+// version' headings and '-' list items. RPM changelogs have no sections.
+type rpmSyntax struct{}
+
+func (rpmSyntax) parseRelease(lines []string, i int) *Release {
+	m := rpmHeadingRegex.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+	if m == nil {
+		return nil
+	}
+	release := &Release{Heading: lines[i], Version: m[2]}
+	if date, err := time.Parse("Mon Jan _2 2006", strings.Join(strings.Fields(m[1]), " ")); err == nil {
+		release.Date = date.Format("2006-01-02")
+	}
+	return release
+}
+
+func (rpmSyntax) parseSection([]string, int) (string, bool) {
+	return "", false
+}
+
+func (rpmSyntax) parseEntry(line string) (string, bool) {
+	if m := listItemRegex.FindStringSubmatch(line); m != nil {
+		// a literal '%' is escaped as '%%' in spec files
+		return strings.ReplaceAll(m[1], "%%", "%"), true
+	}
+	return "", false
+}
+
+func (rpmSyntax) parseDate(string) (string, bool) {
+	return "", false
+}
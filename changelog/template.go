@@ -0,0 +1,384 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"embed"
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/convcommits"
+	"github.com/release-tools/since/vcs"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/release.*.tmpl
+var releaseTemplates embed.FS
+
+// ReleaseData is the data passed to the template that renders a release.
+type ReleaseData struct {
+	// Version is the version of the release, or the unreleased version name.
+	Version string
+
+	// Date is the release date, formatted as YYYY-MM-DD, or empty if
+	// the release is unreleased.
+	Date string
+
+	// Unreleased is true if the release has not been released yet.
+	Unreleased bool
+
+	// Time is the time of the release, or the current time if it is unreleased.
+	Time time.Time
+
+	// Package holds the package details, for package changelog formats.
+	Package PackageData
+
+	// Sections holds the sections of the release, sorted by name, after
+	// any breaking changes section.
+	Sections []SectionData
+
+	// Contributors holds the authors and co-authors of the commits, sorted
+	// by name, if contributors are enabled.
+	Contributors []ContributorData
+}
+
+// SectionData is a section of a release, such as 'Added'.
+type SectionData struct {
+	Name string
+
+	// Commits holds the commits in the section, in the configured entry order.
+	Commits []CommitData
+}
+
+// CommitData is a single commit within a section.
+type CommitData struct {
+	// Message is the first line of the commit message.
+	Message string
+
+	// Type is the conventional commit type, such as 'feat'.
+	Type string
+
+	// Scope is the conventional commit scope, such as 'api', if any.
+	Scope string
+
+	// Description is the message without its type and scope prefix.
+	Description string
+
+	// Text is the entry as it appears in the changelog: the message,
+	// normalised according to the entry options of the changelog config,
+	// or the breaking change footer for breaking changes.
+	Text string
+
+	// Breaking is true if the commit introduces a breaking change, either
+	// with a '!' after its type, or a 'BREAKING CHANGE:' footer.
+	Breaking bool
+
+	// BreakingChange is the text of the 'BREAKING CHANGE:' footer, if any.
+	BreakingChange string
+
+	// Notes holds the extended notes from any 'Release-Note:' trailers,
+	// rendered under the entry.
+	Notes []string
+
+	Hash        string
+	ShortHash   string
+	Author      string
+	AuthorEmail string
+
+	// Date is the author date of the commit, formatted as YYYY-MM-DD.
+	Date string
+
+	// Committed is the time the commit was committed, or its author time if
+	// that is not known. It orders entries chronologically.
+	Committed time.Time
+}
+
+// PackageData holds the package details written to Debian and RPM changelogs.
+type PackageData struct {
+	Name         string
+	Distribution string
+	Urgency      string
+
+	// Maintainer is the name and email of the maintainer, such as
+	// 'Jane Doe <jane@example.com>'.
+	Maintainer string
+}
+
+// Renderer renders releases as changelog sections.
+type Renderer struct {
+	Format   Format
+	Template *template.Template
+	Package  PackageData
+	Entries  cfg.EntryConfig
+
+	// EntryOrder is the order of the entries within each section.
+	EntryOrder EntryOrder
+
+	// Contributors configures the contributors list of each release,
+	// or is nil if contributors are not listed.
+	Contributors *ContributorOptions
+
+	// NextVersion is the version the unreleased changes will be released
+	// as, if known. Package formats need it to render unreleased changes.
+	NextVersion string
+
+	// Unreleased holds entries that are not derived from commits, such as
+	// those written by hand in the Unreleased section of the changelog,
+	// which are added to the sections of the Unreleased release.
+	Unreleased []SectionData
+}
+
+// UnreleasedVersionSuffix is appended to the next version to give the
+// version of unreleased changes in package changelogs, whose versions must
+// be valid. A version with a '~' suffix sorts before the version itself.
+const UnreleasedVersionSuffix = "~unreleased"
+
+// templateFuncs are the functions available to release templates.
+var templateFuncs = template.FuncMap{
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"trim":      strings.TrimSpace,
+	"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"join":      func(sep string, s []string) string { return strings.Join(s, sep) },
+	"underline": func(char, s string) string { return strings.Repeat(char, len(s)) },
+}
+
+// NewRenderer returns a renderer for the given format, using the template
+// configured to render each release, or the built-in template for the
+// format if none is configured. A template file path is resolved against
+// the repository path, if it is relative.
+func NewRenderer(config cfg.SinceConfig, repoPath string, format Format) (*Renderer, error) {
+	tmpl, err := loadTemplate(config, repoPath, format)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := PackageData{
+		Name:         config.Changelog.Package.Name,
+		Distribution: config.Changelog.Package.Distribution,
+		Urgency:      config.Changelog.Package.Urgency,
+		Maintainer:   config.Changelog.Package.Maintainer,
+	}
+	if pkg.Name == "" {
+		if absPath, err := filepath.Abs(repoPath); err == nil {
+			pkg.Name = filepath.Base(absPath)
+		}
+	}
+	if pkg.Distribution == "" {
+		pkg.Distribution = "unstable"
+	}
+	if pkg.Urgency == "" {
+		pkg.Urgency = "medium"
+	}
+	entryOrder, err := ParseEntryOrder(config.Changelog.EntryOrder)
+	if err != nil {
+		return nil, err
+	}
+	contributors, err := newContributorOptions(config.Changelog.Contributors, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{
+		Format:       format,
+		Template:     tmpl,
+		Package:      pkg,
+		Entries:      config.Changelog.Entries,
+		EntryOrder:   entryOrder,
+		Contributors: contributors,
+	}, nil
+}
+
+// loadTemplate returns the template configured to render each release,
+// or the built-in template for the format.
+func loadTemplate(config cfg.SinceConfig, repoPath string, format Format) (*template.Template, error) {
+	var text, name string
+	if config.Changelog.Template != "" {
+		text = config.Changelog.Template
+		name = "inline"
+	} else if config.Changelog.TemplateFile != "" {
+		templateFile := config.Changelog.TemplateFile
+		if !filepath.IsAbs(templateFile) {
+			templateFile = filepath.Join(repoPath, templateFile)
+		}
+		content, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read changelog template: %w", err)
+		}
+		text = string(content)
+		name = filepath.Base(templateFile)
+	} else {
+		content, err := releaseTemplates.ReadFile("templates/release." + string(format) + ".tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("no built-in template for changelog format: %s", format)
+		}
+		text = string(content)
+		name = string(format)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse changelog template: %w", err)
+	}
+	logrus.Tracef("loaded %s changelog template", name)
+	return tmpl, nil
+}
+
+// Render renders each release of the commits using the template,
+// separating them with a blank line.
+func (r *Renderer) Render(
+	commits *[]vcs.TagCommits,
+	groupIntoSections bool,
+	releaseUnreleased bool,
+	unreleasedVersionName string,
+) (string, error) {
+	if commits == nil {
+		logrus.Debug("no commits to render")
+		return "", nil
+	}
+	var releases []string
+	for _, tagCommits := range *commits {
+		data := r.buildReleaseData(tagCommits, groupIntoSections, releaseUnreleased, unreleasedVersionName)
+		if data.Unreleased && r.Format.IsPackage() {
+			if r.NextVersion == "" {
+				return "", fmt.Errorf("unreleased changes cannot be rendered in a %s changelog without the next version", r.Format)
+			}
+			data.Version = r.NextVersion + UnreleasedVersionSuffix
+		}
+		data.Package = r.Package
+		if data.Package.Maintainer == "" {
+			data.Package.Maintainer = latestAuthor(tagCommits)
+		}
+		if r.Contributors != nil {
+			data.Contributors = r.Contributors.list(tagCommits.CommitDetails())
+		}
+
+		var output strings.Builder
+		if err := r.Template.Execute(&output, data); err != nil {
+			return "", fmt.Errorf("failed to render changelog template for version %s: %w", data.Version, err)
+		}
+		releases = append(releases, strings.TrimSpace(output.String()))
+		logrus.Debugf("grouped %d commits for version %s into %d sections\n", len(tagCommits.Commits), tagCommits.Name, len(data.Sections))
+	}
+	return strings.Join(releases, "\n\n"), nil
+}
+
+// latestAuthor returns the name and email of the author of the most
+// recent commit, or an empty string if it is not known.
+func latestAuthor(tagCommits vcs.TagCommits) string {
+	for _, detail := range tagCommits.CommitDetails() {
+		if detail.Author != "" {
+			return fmt.Sprintf("%s <%s>", detail.Author, detail.AuthorEmail)
+		}
+	}
+	return ""
+}
+
+// buildReleaseData returns the template data for the commits of a tag.
+func (r *Renderer) buildReleaseData(
+	tagCommits vcs.TagCommits,
+	groupIntoSections bool,
+	releaseUnreleased bool,
+	unreleasedVersionName string,
+) ReleaseData {
+	var data ReleaseData
+	if tagCommits.Name == vcs.UnreleasedVersionName {
+		data.Unreleased = !releaseUnreleased
+		data.Version = unreleasedVersionName
+	} else {
+		data.Version = strings.TrimPrefix(tagCommits.Name, "v")
+	}
+	if !data.Unreleased {
+		data.Date = tagCommits.Date.Format("2006-01-02")
+	}
+	data.Time = tagCommits.Date
+
+	categorised := make(map[string][]CommitData)
+	for _, detail := range tagCommits.CommitDetails() {
+		trailers := parseEntryTrailers(detail.Body)
+		commit := buildCommitData(detail)
+		commit.Text = normaliseEntry(commit, r.Entries, r.Format)
+		commit.Notes = trailers.notes
+		category := commit.Type
+		if groupIntoSections {
+			if commit.Breaking {
+				category = BreakingChangesSectionName
+				if commit.BreakingChange != "" {
+					commit.Text = commit.BreakingChange
+				}
+			} else {
+				category = mapTypeToSection(category)
+			}
+			if trailers.section != "" {
+				category = trailers.section
+			}
+		}
+		if trailers.text != "" {
+			commit.Text = trailers.text
+		}
+		categorised[category] = append(categorised[category], commit)
+	}
+	if tagCommits.Name == vcs.UnreleasedVersionName {
+		addUnreleasedEntries(categorised, r.Unreleased, tagCommits.Date)
+	}
+
+	categories := maps.Keys(categorised)
+	sort.SliceStable(categories, func(i, j int) bool {
+		return sectionLess(categories[i], categories[j])
+	})
+	for _, category := range categories {
+		items := categorised[category]
+		sortCommits(items, r.EntryOrder)
+		data.Sections = append(data.Sections, SectionData{Name: category, Commits: items})
+	}
+	return data
+}
+
+// buildCommitData returns the template data for a commit.
+func buildCommitData(detail vcs.CommitDetail) CommitData {
+	commit := CommitData{
+		Message:     detail.Message,
+		Type:        convcommits.GetType(detail.Message),
+		Scope:       convcommits.GetScope(detail.Message),
+		Description: convcommits.GetDescription(detail.Message),
+		Hash:        detail.Hash,
+		ShortHash:   detail.Hash,
+		Author:      detail.Author,
+		AuthorEmail: detail.AuthorEmail,
+
+		BreakingChange: convcommits.GetBreakingChange(detail.Body),
+	}
+	commit.Breaking = commit.Type == convcommits.BreakingChangeType || commit.BreakingChange != ""
+	if len(commit.ShortHash) > 7 {
+		commit.ShortHash = commit.ShortHash[:7]
+	}
+	if !detail.Date.IsZero() {
+		commit.Date = detail.Date.Format("2006-01-02")
+	}
+	commit.Committed = detail.CommitDate
+	if commit.Committed.IsZero() {
+		commit.Committed = detail.Date
+	}
+	return commit
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: "v1.0.0", Date: time.Date(2023, 8, 28, 0, 0, 0, 0, time.UTC)},
+			Commits: []string{"fix(ui): correct layout", "feat(api): add pagination"},
+			Details: []vcs.CommitDetail{
+				{Hash: "0123456789abcdef0123456789abcdef01234567", Message: "fix(ui): correct layout", Author: "Jane", AuthorEmail: "jane@example.com"},
+				{Hash: "89abcdef0123456789abcdef0123456789abcdef", Message: "feat(api): add pagination", Author: "Joe", AuthorEmail: "joe@example.com"},
+			},
+		},
+		{
+			TagMeta: vcs.TagMeta{Name: "v0.1.0", Date: time.Date(2023, 8, 27, 0, 0, 0, 0, time.UTC)},
+			Commits: []string{"initial import"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "commit metadata",
+			template: `= {{ .Version }} ({{ .Date }})
+{{ range .Sections }}{{ .Name | upper }}
+{{ range .Commits }}* {{ if .Scope }}[{{ .Scope }}] {{ end }}{{ .Description }}{{ if .Hash }} ({{ .ShortHash }} by {{ .Author }}){{ end }}
+{{ end }}{{ end }}`,
+			want: `= 1.0.0 (2023-08-28)
+ADDED
+* [api] add pagination (89abcde by Joe)
+FIXED
+* [ui] correct layout (0123456 by Jane)
+
+= 0.1.0 (2023-08-27)
+OTHER
+* initial import`,
+		},
+		{
+			name:     "missing field",
+			template: "{{ .Missing }}",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := NewRenderer(cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Template: tt.template}}, "", FormatMarkdown)
+			if err != nil {
+				t.Fatalf("NewRenderer() error = %v", err)
+			}
+			got, err := renderer.Render(&commits, true, false, vcs.UnreleasedVersionName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Render() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoDir, "release.tmpl"), []byte("# {{ .Version }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("default template", func(t *testing.T) {
+		renderer, err := NewRenderer(cfg.SinceConfig{}, repoDir, FormatMarkdown)
+		if err != nil {
+			t.Fatalf("NewRenderer() error = %v", err)
+		}
+		if renderer.Template.Name() != "markdown" {
+			t.Errorf("NewRenderer() template = %v, want markdown", renderer.Template.Name())
+		}
+		if renderer.Package.Name != filepath.Base(repoDir) || renderer.Package.Distribution != "unstable" || renderer.Package.Urgency != "medium" {
+			t.Errorf("NewRenderer() package = %+v, want defaults", renderer.Package)
+		}
+	})
+	t.Run("relative template file", func(t *testing.T) {
+		renderer, err := NewRenderer(cfg.SinceConfig{Changelog: cfg.ChangelogConfig{TemplateFile: "release.tmpl"}}, repoDir, FormatMarkdown)
+		if err != nil {
+			t.Fatalf("NewRenderer() error = %v", err)
+		}
+		if renderer.Template.Name() != "release.tmpl" {
+			t.Errorf("NewRenderer() template = %v, want release.tmpl", renderer.Template.Name())
+		}
+	})
+	t.Run("missing template file", func(t *testing.T) {
+		if _, err := NewRenderer(cfg.SinceConfig{Changelog: cfg.ChangelogConfig{TemplateFile: "missing.tmpl"}}, repoDir, FormatMarkdown); err == nil {
+			t.Error("NewRenderer() expected error for missing file")
+		}
+	})
+	t.Run("invalid template", func(t *testing.T) {
+		if _, err := NewRenderer(cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Template: "{{ .Version "}}, repoDir, FormatMarkdown); err == nil {
+			t.Error("NewRenderer() expected error for invalid template")
+		}
+	})
+}
+
+func TestRenderer_Render_breakingChanges(t *testing.T) {
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: "v2.0.0", Date: time.Date(2023, 8, 28, 0, 0, 0, 0, time.UTC)},
+			Commits: []string{"feat: add pagination", "feat(api)!: remove v1 endpoints", "refactor: load YAML config"},
+			Details: []vcs.CommitDetail{
+				{Message: "feat: add pagination"},
+				{Message: "feat(api)!: remove v1 endpoints"},
+				{Message: "refactor: load YAML config", Body: "BREAKING CHANGE: rename 'since.yml' to 'since.yaml'"},
+			},
+		},
+	}
+
+	got, err := defaultRenderer.Render(&commits, true, false, vcs.UnreleasedVersionName)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `## [2.0.0] - 2023-08-28
+### ⚠ Breaking Changes
+- feat(api)!: remove v1 endpoints
+- rename 'since.yml' to 'since.yaml'
+
+### Added
+- feat: add pagination`
+	if got != want {
+		t.Errorf("Render() got = %v, want %v", got, want)
+	}
+}
+
+func TestRenderer_Render_trailers(t *testing.T) {
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: "v1.1.0", Date: time.Date(2023, 8, 28, 0, 0, 0, 0, time.UTC)},
+			Commits: []string{"feat: add pagination", "fix: bump parser", "refactor!: drop v1 API"},
+			Details: []vcs.CommitDetail{
+				{Message: "feat: add pagination", Body: "Release-Note: Pages hold 50 items by default.\nRelease-Note: Use ?size= to change it."},
+				{Message: "fix: bump parser", Body: "Changelog: Fix CVE-2023-1234 in the YAML parser\nChangelog-Section: Security"},
+				{Message: "refactor!: drop v1 API", Body: "Changelog: Remove the v1 API"},
+			},
+		},
+	}
+
+	got, err := defaultRenderer.Render(&commits, true, false, vcs.UnreleasedVersionName)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `## [1.1.0] - 2023-08-28
+### ⚠ Breaking Changes
+- Remove the v1 API
+
+### Added
+- feat: add pagination
+  Pages hold 50 items by default.
+  Use ?size= to change it.
+
+### Security
+- Fix CVE-2023-1234 in the YAML parser`
+	if got != want {
+		t.Errorf("Render() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/convcommits"
+	"github.com/release-tools/since/vcs"
+	"strings"
+)
+
+// Trailers in the body of a commit message that control its changelog entry.
+const (
+	// ChangelogTrailer replaces the text of the entry. Commits whose value
+	// is 'skip' are left out when the commits are fetched.
+	ChangelogTrailer = vcs.ChangelogTrailer
+
+	// ChangelogSectionTrailer forces the section of the entry, such as 'Security'.
+	ChangelogSectionTrailer = "Changelog-Section"
+
+	// ReleaseNoteTrailer adds a line of extended notes under the entry.
+	ReleaseNoteTrailer = "Release-Note"
+)
+
+// entryTrailers holds the trailers of a commit that control its entry.
+type entryTrailers struct {
+	// text replaces the text of the entry, if set.
+	text string
+
+	// section forces the section of the entry, if set.
+	section string
+
+	// notes are the extended notes listed under the entry.
+	notes []string
+}
+
+// parseEntryTrailers returns the trailers in the body of a commit message
+// that control its entry. If a trailer that replaces a value is given more
+// than once, the last one is used.
+func parseEntryTrailers(body string) entryTrailers {
+	var trailers entryTrailers
+	if values := convcommits.GetTrailerValues(body, ChangelogTrailer); len(values) > 0 {
+		if last := values[len(values)-1]; !strings.EqualFold(last, vcs.ChangelogSkipValue) {
+			trailers.text = last
+		}
+	}
+	if values := convcommits.GetTrailerValues(body, ChangelogSectionTrailer); len(values) > 0 {
+		trailers.section = values[len(values)-1]
+	}
+	trailers.notes = convcommits.GetTrailerValues(body, ReleaseNoteTrailer)
+	return trailers
+}
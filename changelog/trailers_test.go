@@ -0,0 +1,63 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseEntryTrailers(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want entryTrailers
+	}{
+		{
+			name: "no trailers",
+			body: "Some explanation.",
+			want: entryTrailers{},
+		},
+		{
+			name: "replace text",
+			body: "Changelog: Faster start-up on large repositories",
+			want: entryTrailers{text: "Faster start-up on large repositories"},
+		},
+		{
+			name: "skip",
+			body: "Internal clean-up.\n\nchangelog: Skip",
+			want: entryTrailers{},
+		},
+		{
+			name: "section and notes",
+			body: "Changelog-Section: Security\nRelease-Note: Rotate your API keys.\nRelease-Note: See the upgrade guide.",
+			want: entryTrailers{section: "Security", notes: []string{"Rotate your API keys.", "See the upgrade guide."}},
+		},
+		{
+			name: "last value wins",
+			body: "Changelog: first\nChangelog: second",
+			want: entryTrailers{text: "second"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseEntryTrailers(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEntryTrailers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/semver"
+	"github.com/release-tools/since/vcs"
+	"github.com/sirupsen/logrus"
+	"os"
+	"strings"
+	"time"
+)
+
+// otherSectionName is the section for changes that do not map to
+// any other section.
+const otherSectionName = "Other"
+
+// unreleasedEntries returns the entries written by hand in an Unreleased
+// section, as template data grouped by section, so they can be rendered
+// with the entries of the commits. List items that are not under any
+// section are in the Other section, and the indented lines of an entry
+// are its notes.
+func unreleasedEntries(unreleased *Release) []SectionData {
+	if unreleased == nil {
+		return nil
+	}
+	var sections []SectionData
+	for _, section := range unreleasedSections(unreleased) {
+		data := SectionData{Name: section.Name}
+		for _, entry := range section.Entries {
+			data.Commits = append(data.Commits, CommitData{
+				Message:     entry.Text,
+				Description: entry.Text,
+				Text:        entry.Text,
+				Notes:       entry.Details,
+			})
+		}
+		sections = append(sections, data)
+	}
+	return sections
+}
+
+// unreleasedBump returns the version bump required by the entries written
+// by hand: a major release for breaking changes, a minor release for
+// added features, or otherwise a patch release.
+func unreleasedBump(sections []SectionData) semver.Component {
+	bump := semver.ComponentNone
+	for _, section := range sections {
+		if len(section.Commits) == 0 {
+			continue
+		}
+		switch {
+		case section.Name == BreakingChangesSectionName:
+			bump = semver.MaxComponent(bump, semver.ComponentMajor)
+		case strings.EqualFold(section.Name, "Added"):
+			bump = semver.MaxComponent(bump, semver.ComponentMinor)
+		default:
+			bump = semver.MaxComponent(bump, semver.ComponentPatch)
+		}
+	}
+	return bump
+}
+
+// minimumBump returns the smallest version bump of the next release, as
+// required by the fragments and the entries written by hand.
+func minimumBump(fragments []Fragment, manual []SectionData) semver.Component {
+	return semver.MaxComponent(fragmentsBump(fragments), unreleasedBump(manual))
+}
+
+// MinimumBump returns the smallest version bump of the next release, as
+// required by the fragments in the repository and the entries written by
+// hand under Unreleased in the changelog file, if it exists.
+func MinimumBump(config cfg.SinceConfig, repoPath string, changelogFile string) (semver.Component, error) {
+	fragments, err := ReadFragments(config, repoPath)
+	if err != nil {
+		return "", err
+	}
+	var manual []SectionData
+	if lines, err := ReadFile(changelogFile); err == nil {
+		format, err := ResolveFormat(config, changelogFile)
+		if err != nil {
+			return "", err
+		}
+		manual = unreleasedEntries(splitDocument(ParseDocumentAs(lines, format)).Unreleased)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read changelog file: %s: %w", changelogFile, err)
+	}
+	return minimumBump(fragments, manual), nil
+}
+
+// addUnreleasedEntries adds the entries written by hand to the sections of
+// the commits, by section name, skipping those that duplicate the text or
+// message of an existing entry.
+func addUnreleasedEntries(categorised map[string][]CommitData, sections []SectionData, date time.Time) {
+	var added int
+	for _, section := range sections {
+		category := section.Name
+		for name := range categorised {
+			if strings.EqualFold(name, section.Name) {
+				category = name
+				break
+			}
+		}
+		for _, entry := range section.Commits {
+			if containsCommit(categorised[category], entry.Text) {
+				logrus.Tracef("skipping duplicate unreleased entry: %s", entry.Text)
+				continue
+			}
+			if entry.Committed.IsZero() {
+				// entries without a commit are the newest changes of the release
+				entry.Committed = date
+			}
+			categorised[category] = append(categorised[category], entry)
+			added++
+		}
+	}
+	if added > 0 {
+		logrus.Debugf("added %d unreleased entries", added)
+	}
+}
+
+// ensureUnreleased adds an empty Unreleased release before the releases
+// of the commits, if there is none, to hold the entries that are not
+// derived from commits.
+func ensureUnreleased(commits *[]vcs.TagCommits) {
+	if len(*commits) > 0 && (*commits)[0].Name == vcs.UnreleasedVersionName {
+		return
+	}
+	unreleased := vcs.TagCommits{TagMeta: vcs.TagMeta{Name: vcs.UnreleasedVersionName, Date: time.Now()}}
+	*commits = append([]vcs.TagCommits{unreleased}, *commits...)
+}
+
+// unreleasedSections returns the sections of the Unreleased release that
+// have entries, including an Other section for any list items that are
+// not under a section heading.
+func unreleasedSections(unreleased *Release) []*Section {
+	var sections []*Section
+	intro := &Section{Name: otherSectionName}
+	for _, line := range unreleased.Intro {
+		intro.addLine(strings.TrimRight(line, "\r"))
+	}
+	if len(intro.Entries) > 0 {
+		sections = append(sections, intro)
+	}
+	for _, section := range unreleased.Sections {
+		if len(section.Entries) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// findSection returns the section of the release with the given name,
+// compared case-insensitively, or nil if there is none.
+func findSection(release *Release, name string) *Section {
+	for _, section := range release.Sections {
+		if strings.EqualFold(section.Name, name) {
+			return section
+		}
+	}
+	return nil
+}
+
+// containsCommit returns true if the commits include one whose text or
+// message is the same as the given text, ignoring case, surrounding
+// whitespace and a trailing full stop.
+func containsCommit(commits []CommitData, text string) bool {
+	text = normaliseEntryText(text)
+	for _, existing := range commits {
+		if normaliseEntryText(existing.Text) == text || normaliseEntryText(existing.Message) == text {
+			return true
+		}
+	}
+	return false
+}
+
+func normaliseEntryText(text string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(text), "."))
+}
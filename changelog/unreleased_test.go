@@ -0,0 +1,189 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/semver"
+	"github.com/release-tools/since/vcs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderer_Render_unreleasedEntries(t *testing.T) {
+	rendered := `## [0.2.0] - 2024-01-02
+### Added
+- feat: generated feature
+
+### Fixed
+- fix: generated fix`
+
+	tests := []struct {
+		name       string
+		unreleased string
+		template   string
+		want       string
+	}{
+		{
+			name: "no unreleased section",
+			want: rendered,
+		},
+		{
+			name:       "empty unreleased section",
+			unreleased: "## [Unreleased]\n### Added\n",
+			want:       rendered,
+		},
+		{
+			name: "merge into matching sections",
+			unreleased: `## [Unreleased]
+### Added
+- Manual feature
+  with details
+
+### Security
+- Patched a vulnerability.
+
+### fixed
+- Manual fix`,
+			want: `## [0.2.0] - 2024-01-02
+### Added
+- Manual feature
+  with details
+- feat: generated feature
+
+### Fixed
+- Manual fix
+- fix: generated fix
+
+### Security
+- Patched a vulnerability.`,
+		},
+		{
+			name: "skip duplicates",
+			unreleased: `## [Unreleased]
+### Added
+- feat: Generated feature.
+- feat: generated feature
+
+### Fixed
+- fix: generated fix`,
+			want: rendered,
+		},
+		{
+			name: "entries outside a section",
+			unreleased: `## [Unreleased]
+- Something else
+
+### Fixed
+- Manual fix`,
+			want: `## [0.2.0] - 2024-01-02
+### Added
+- feat: generated feature
+
+### Fixed
+- Manual fix
+- fix: generated fix
+
+### Other
+- Something else`,
+		},
+		{
+			name: "custom template",
+			unreleased: `## [Unreleased]
+### Security
+- Patched a vulnerability.`,
+			template: "# {{ .Version }}\n{{ range .Sections }}{{ $section := .Name }}{{ range .Commits }}* [{{ $section }}] {{ .Text }}\n{{ end }}{{ end }}",
+			want:     "# 0.2.0\n* [Added] feat: generated feature\n* [Fixed] fix: generated fix\n* [Security] Patched a vulnerability.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var unreleased *Release
+			if tt.unreleased != "" {
+				unreleased = ParseDocument(strings.Split(tt.unreleased, "\n")).Releases[0]
+			}
+			config := cfg.SinceConfig{Changelog: cfg.ChangelogConfig{Template: tt.template}}
+			renderer, err := NewRenderer(config, "", FormatMarkdown)
+			if err != nil {
+				t.Fatal(err)
+			}
+			renderer.Unreleased = unreleasedEntries(unreleased)
+
+			commits := []vcs.TagCommits{{
+				TagMeta: vcs.TagMeta{Name: vcs.UnreleasedVersionName, Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+				Commits: []string{"feat: generated feature", "fix: generated fix"},
+			}}
+			got, err := renderer.Render(&commits, true, true, "0.2.0")
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinimumBump(t *testing.T) {
+	repoDir := t.TempDir()
+	changelogFile := path.Join(repoDir, "CHANGELOG.md")
+	content := "# Changelog\n\n## [Unreleased]\n### Added\n- Support for importing projects\n\n## [1.0.0] - 2024-01-01\n### Fixed\n- A bug\n"
+	if err := os.WriteFile(changelogFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the entries written by hand require a bump
+	bump, err := MinimumBump(cfg.SinceConfig{}, repoDir, changelogFile)
+	if err != nil {
+		t.Fatalf("MinimumBump() error = %v", err)
+	}
+	if bump != semver.ComponentMinor {
+		t.Errorf("MinimumBump() = %v, want %v", bump, semver.ComponentMinor)
+	}
+
+	writeFragment(t, path.Join(repoDir, ".changes"), "api.md", "---\nbump: major\n---\nRemoves the legacy API.\n")
+	if bump, err = MinimumBump(cfg.SinceConfig{}, repoDir, changelogFile); err != nil {
+		t.Fatalf("MinimumBump() error = %v", err)
+	}
+	if bump != semver.ComponentMajor {
+		t.Errorf("MinimumBump() with a fragment = %v, want %v", bump, semver.ComponentMajor)
+	}
+}
+
+func Test_unreleasedBump(t *testing.T) {
+	tests := []struct {
+		name       string
+		unreleased string
+		want       semver.Component
+	}{
+		{name: "no entries", unreleased: "## [Unreleased]\n### Added\n", want: semver.ComponentNone},
+		{name: "fixes", unreleased: "## [Unreleased]\n### Fixed\n- A fix\n", want: semver.ComponentPatch},
+		{name: "features", unreleased: "## [Unreleased]\n- Other\n### Added\n- A feature\n", want: semver.ComponentMinor},
+		{name: "breaking changes", unreleased: "## [Unreleased]\n### " + BreakingChangesSectionName + "\n- Removed the API\n", want: semver.ComponentMajor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unreleased := ParseDocument(strings.Split(tt.unreleased, "\n")).Releases[0]
+			if got := unreleasedBump(unreleasedEntries(unreleased)); got != tt.want {
+				t.Errorf("unreleasedBump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
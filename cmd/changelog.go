@@ -18,16 +18,25 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/vcs"
 	"github.com/sirupsen/logrus"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var changelogArgs struct {
+	cache             bool
 	changelogFile     string
+	dateType          string
 	excludeTagCommits bool
 	outputFile        string
+	sinceDate         string
+	skipCherryPicks   bool
+	untilDate         string
 }
 
 // changelogCmd represents the changelog command
@@ -43,6 +52,25 @@ func init() {
 	changelogCmd.PersistentFlags().StringVarP(&changelogArgs.changelogFile, "changelog", "c", "CHANGELOG.md", "Path to changelog file")
 	changelogCmd.PersistentFlags().StringVar(&changelogArgs.outputFile, "output-file", "", "Path to output file (otherwise stdout)")
 	changelogCmd.PersistentFlags().BoolVar(&changelogArgs.excludeTagCommits, "exclude-tag-commits", false, "Exclude tag commits in the changelog")
+	changelogCmd.PersistentFlags().BoolVar(&changelogArgs.skipCherryPicks, "skip-cherry-picks", false, "Skip commits that copy a commit already released under another tag")
+	changelogCmd.PersistentFlags().BoolVar(&changelogArgs.cache, "cache", false, "Cache the parsed history in the git directory, to speed up later runs")
+	addDateFlags(changelogCmd.PersistentFlags(), &changelogArgs.sinceDate, &changelogArgs.untilDate, &changelogArgs.dateType)
+}
+
+// addDateFlags adds the flags to filter commits by date.
+func addDateFlags(flags *pflag.FlagSet, sinceDate *string, untilDate *string, dateType *string) {
+	flags.StringVar(sinceDate, "since-date", "", "Only include commits dated on or after this date (YYYY-MM-DD or RFC 3339)")
+	flags.StringVar(untilDate, "until-date", "", "Only include commits dated on or before this date (YYYY-MM-DD or RFC 3339)")
+	flags.StringVar(dateType, "date-type", string(vcs.DateCommitter), "Which commit date to filter by (committer|author)")
+}
+
+// newDateFilter returns the filter for the date flags.
+func newDateFilter(sinceDate string, untilDate string, dateType string) (vcs.DateFilter, error) {
+	parsedType, err := vcs.ParseDateType(dateType)
+	if err != nil {
+		return vcs.DateFilter{}, err
+	}
+	return vcs.NewDateFilter(sinceDate, untilDate, parsedType)
 }
 
 func getWorkingDir() (string, error) {
@@ -53,6 +81,33 @@ func getWorkingDir() (string, error) {
 	return workingDir, nil
 }
 
+// loadChangelogConfig loads the config from the repository, overriding
+// the changelog format if one is given.
+func loadChangelogConfig(repoPath string, format string) (cfg.SinceConfig, error) {
+	config, err := cfg.LoadConfig(repoPath)
+	if err != nil {
+		return cfg.SinceConfig{}, err
+	}
+	if format != "" {
+		parsed, err := changelog.ParseFormat(format)
+		if err != nil {
+			return cfg.SinceConfig{}, err
+		}
+		config.Changelog.Format = string(parsed)
+	}
+	return config, nil
+}
+
+// resolveChangelogFormat returns the format of the changelog file, as set
+// in the config in the given directory, or detected from the file name.
+func resolveChangelogFormat(dir string, changelogFile string) (changelog.Format, error) {
+	config, err := cfg.LoadConfig(dir)
+	if err != nil {
+		return "", err
+	}
+	return changelog.ResolveFormat(config, changelogFile)
+}
+
 // writeOutput writes the output to the output file, or stdout if not set.
 func writeOutput(output string) error {
 	outputFile := changelogArgs.outputFile
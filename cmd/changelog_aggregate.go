@@ -0,0 +1,95 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/vcs"
+	"github.com/spf13/cobra"
+)
+
+var aggregateArgs struct {
+	format   string
+	manifest string
+	orderBy  string
+	unique   bool
+}
+
+// aggregateCmd represents the aggregate command
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate",
+	Short: "Combine the changes of several repositories into one document",
+	Long: `Generates a release document combining the changes of each
+repository listed in a manifest file, grouped by repository, then by
+section, then prints it to stdout, or output-file, if specified.
+
+Each repository in the manifest has a path, relative to the manifest
+file, and either a tag, to include the changes released in that tag,
+or a range, with from and/or to tags. For example:
+
+  title: Product 2.0
+  repositories:
+    - name: api
+      path: ../api
+      tag: 1.4.0
+    - path: ../web
+      from: 2.1.0
+      to: 2.3.0`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dates, err := newDateFilter(changelogArgs.sinceDate, changelogArgs.untilDate, changelogArgs.dateType)
+		if err != nil {
+			return err
+		}
+		commitCfg := vcs.CommitConfig{
+			ExcludeTagCommits: changelogArgs.excludeTagCommits,
+			UniqueOnly:        aggregateArgs.unique,
+			SkipCherryPicks:   changelogArgs.skipCherryPicks,
+			UseCache:          changelogArgs.cache,
+			Dates:             dates,
+		}
+		return aggregateChangelog(commitCfg, aggregateArgs.manifest, aggregateArgs.format, vcs.TagOrderBy(aggregateArgs.orderBy))
+	},
+}
+
+func init() {
+	changelogCmd.AddCommand(aggregateCmd)
+
+	aggregateCmd.Flags().StringVarP(&aggregateArgs.manifest, "manifest", "m", "", "Path to the manifest file listing the repositories")
+	aggregateCmd.Flags().StringVarP(&aggregateArgs.format, "format", "f", string(changelog.FormatMarkdown), "Document format (markdown|asciidoc|rst)")
+	aggregateCmd.Flags().StringVarP(&aggregateArgs.orderBy, "order-by", "o", string(vcs.TagOrderSemver), "How to determine the tag before each repository's tag (alphabetical|commit-date|semver)")
+	aggregateCmd.Flags().BoolVar(&aggregateArgs.unique, "unique", true, "De-duplicate commit messages")
+	_ = aggregateCmd.MarkFlagRequired("manifest")
+}
+
+func aggregateChangelog(commitCfg vcs.CommitConfig, manifestFile string, format string, orderBy vcs.TagOrderBy) error {
+	parsedFormat, err := changelog.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	manifest, err := changelog.LoadManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+	aggregated, err := changelog.AggregateChangelog(commitCfg, manifest, parsedFormat, orderBy)
+	if err != nil {
+		return err
+	}
+	return writeOutput(aggregated)
+}
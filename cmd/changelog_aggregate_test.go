@@ -0,0 +1,69 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/release-tools/since/vcs"
+)
+
+func Test_aggregateChangelog(t *testing.T) {
+	t.Run("writes the changes of each repository to the output file", func(t *testing.T) {
+		repoDir, _ := createChangelogTestRepo(t)
+		manifestFile := filepath.Join(repoDir, "manifest.yaml")
+		manifest := "title: Product\nrepositories:\n  - name: core\n    path: .\n    from: 0.1.0\n"
+		if err := os.WriteFile(manifestFile, []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		outPath := filepath.Join(t.TempDir(), "aggregate.md")
+		changelogArgs.outputFile = outPath
+		defer func() { changelogArgs.outputFile = "" }()
+
+		err := aggregateChangelog(vcs.CommitConfig{UniqueOnly: true}, manifestFile, "markdown", vcs.TagOrderSemver)
+		if err != nil {
+			t.Fatalf("aggregateChangelog() error = %v", err)
+		}
+
+		content, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatalf("failed to read aggregate output: %v", err)
+		}
+		got := string(content)
+		if !strings.Contains(got, "# Product\n\n## core 0.1.0...HEAD\n### Added\n- feat: add a shiny new feature") {
+			t.Errorf("aggregate output missing the repository changes:\n%s", got)
+		}
+	})
+
+	t.Run("returns error for a missing manifest", func(t *testing.T) {
+		err := aggregateChangelog(vcs.CommitConfig{}, filepath.Join(t.TempDir(), "missing.yaml"), "markdown", vcs.TagOrderSemver)
+		if err == nil {
+			t.Error("aggregateChangelog() expected error for a missing manifest")
+		}
+	})
+
+	t.Run("returns error for an unsupported format", func(t *testing.T) {
+		err := aggregateChangelog(vcs.CommitConfig{}, "manifest.yaml", "pdf", vcs.TagOrderSemver)
+		if err == nil {
+			t.Error("aggregateChangelog() expected error for an unsupported format")
+		}
+	})
+}
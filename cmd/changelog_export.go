@@ -0,0 +1,111 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/release-tools/since/changelog"
+	"github.com/spf13/cobra"
+)
+
+var exportArgs struct {
+	format        string
+	version       string
+	from          string
+	to            string
+	fromExclusive bool
+	toExclusive   bool
+}
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the changelog as structured data",
+	Long: `Exports the versions in a changelog file, with their dates,
+sections and entries, as JSON or YAML.
+All versions are exported, unless a version or range is specified.
+Prints it to stdout, or output-file, if specified.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workingDir, err := getWorkingDir()
+		if err != nil {
+			return err
+		}
+		changelogFile := changelog.ResolveChangelogFile(workingDir, changelogArgs.changelogFile)
+		changelogFormat, err := resolveChangelogFormat(workingDir, changelogFile)
+		if err != nil {
+			return err
+		}
+		versionRange := changelog.VersionRange{
+			From:          exportArgs.from,
+			To:            exportArgs.to,
+			FromExclusive: exportArgs.fromExclusive,
+			ToExclusive:   exportArgs.toExclusive,
+		}
+		output, err := exportChangelog(changelogFile, changelogFormat, changelog.ExportFormat(exportArgs.format), exportArgs.version, versionRange)
+		if err != nil {
+			return err
+		}
+		return writeOutput(output)
+	},
+}
+
+func init() {
+	changelogCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportArgs.format, "format", "f", string(changelog.ExportFormatJSON), "Output format (json|yaml)")
+	exportCmd.Flags().StringVarP(&exportArgs.version, "version", "v", "", "Only export this version")
+	exportCmd.Flags().StringVar(&exportArgs.from, "from", "", "Export all versions from this version")
+	exportCmd.Flags().StringVar(&exportArgs.to, "to", "", "Export all versions up to this version")
+	exportCmd.Flags().BoolVar(&exportArgs.fromExclusive, "from-exclusive", false, "Exclude the --from version from the range")
+	exportCmd.Flags().BoolVar(&exportArgs.toExclusive, "to-exclusive", false, "Exclude the --to version from the range")
+}
+
+func exportChangelog(
+	changelogFile string,
+	changelogFormat changelog.Format,
+	format changelog.ExportFormat,
+	version string,
+	versionRange changelog.VersionRange,
+) (string, error) {
+	doc, err := changelog.ReadDocument(changelogFile, changelogFormat)
+	if err != nil {
+		return "", err
+	}
+
+	releases := doc.Releases
+	switch {
+	case version != "" && (versionRange.From != "" || versionRange.To != ""):
+		return "", fmt.Errorf("--version cannot be used with --from or --to")
+
+	case version != "":
+		release := doc.FindRelease(version)
+		if release == nil {
+			return "", fmt.Errorf("could not find version %s in changelog", version)
+		}
+		releases = []*changelog.Release{release}
+
+	case versionRange.From != "" || versionRange.To != "":
+		releases, err = doc.ReleasesInRange(versionRange)
+		if err != nil {
+			return "", err
+		}
+	}
+	return changelog.ExportReleases(doc, releases).Marshal(format)
+}
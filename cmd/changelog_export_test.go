@@ -0,0 +1,123 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/release-tools/since/changelog"
+)
+
+func Test_exportChangelog(t *testing.T) {
+	type args struct {
+		format       changelog.ExportFormat
+		version      string
+		versionRange changelog.VersionRange
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "export version as json",
+			args: args{format: changelog.ExportFormatJSON, version: "1.2.0"},
+			want: `{
+  "releases": [
+    {
+      "version": "1.2.0",
+      "date": "2023-03-07",
+      "link": "https://example.com/compare/1.1.0...1.2.0",
+      "sections": [
+        {
+          "name": "Fixed",
+          "entries": [
+            {
+              "text": "fix: second fix."
+            }
+          ]
+        }
+      ]
+    }
+  ],
+  "links": [
+    {
+      "label": "1.2.0",
+      "url": "https://example.com/compare/1.1.0...1.2.0"
+    }
+  ]
+}`,
+		},
+		{
+			name: "export range as yaml",
+			args: args{format: changelog.ExportFormatYAML, versionRange: changelog.VersionRange{To: "1.1.0"}},
+			want: `releases:
+  - version: 1.1.0
+    date: "2023-03-06"
+    sections:
+      - name: Added
+        entries:
+          - text: 'feat: second feature.'
+  - version: 1.0.0
+    date: "2023-03-05"
+    sections:
+      - name: Added
+        entries:
+          - text: 'feat: first feature.'`,
+		},
+		{
+			name:    "unknown version",
+			args:    args{format: changelog.ExportFormatJSON, version: "9.9.9"},
+			wantErr: true,
+		},
+		{
+			name:    "version and range",
+			args:    args{format: changelog.ExportFormatJSON, version: "1.2.0", versionRange: changelog.VersionRange{From: "1.0.0"}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported format",
+			args:    args{format: "xml"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exportChangelog("testdata/multiple.md", changelog.FormatMarkdown, tt.args.format, tt.args.version, tt.args.versionRange)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("exportChangelog() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("exportChangelog() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_exportChangelog_allVersions(t *testing.T) {
+	got, err := exportChangelog("testdata/multiple.md", changelog.FormatMarkdown, changelog.ExportFormatJSON, "", changelog.VersionRange{})
+	if err != nil {
+		t.Fatalf("exportChangelog() error = %v", err)
+	}
+	// the Unreleased section is included when no version or range is given
+	if !strings.Contains(got, `"unreleased": true`) {
+		t.Errorf("exportChangelog() missing unreleased section: %s", got)
+	}
+}
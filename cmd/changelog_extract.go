@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"github.com/release-tools/since/changelog"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +25,11 @@ import (
 var extractArgs struct {
 	version       string
 	includeHeader bool
+	from          string
+	to            string
+	fromExclusive bool
+	toExclusive   bool
+	merge         bool
 }
 
 // extractCmd represents the extract command
@@ -32,6 +38,11 @@ var extractCmd = &cobra.Command{
 	Short: "Extract changes for a given version",
 	Long: `Extracts changes for a given version in a changelog file.
 If no version is specified, the most recent version is used.
+
+Alternatively, extracts the changes for every version in a range,
+using --from and/or --to. With --merge, the same-named sections of
+those versions are combined.
+
 Prints it to stdout, or output-file, if specified.`,
 	Args:          cobra.NoArgs,
 	SilenceUsage:  true,
@@ -42,7 +53,29 @@ Prints it to stdout, or output-file, if specified.`,
 			return err
 		}
 		changelogFile := changelog.ResolveChangelogFile(workingDir, changelogArgs.changelogFile)
-		changes, err := printChanges(changelogFile, extractArgs.version, extractArgs.includeHeader)
+		format, err := resolveChangelogFormat(workingDir, changelogFile)
+		if err != nil {
+			return err
+		}
+
+		var changes string
+		if extractArgs.from != "" || extractArgs.to != "" {
+			if extractArgs.version != "" {
+				return fmt.Errorf("--version cannot be used with --from or --to")
+			}
+			versionRange := changelog.VersionRange{
+				From:          extractArgs.from,
+				To:            extractArgs.to,
+				FromExclusive: extractArgs.fromExclusive,
+				ToExclusive:   extractArgs.toExclusive,
+			}
+			changes, err = printChangesInRange(changelogFile, format, versionRange, extractArgs.includeHeader, extractArgs.merge)
+		} else {
+			if extractArgs.merge {
+				return fmt.Errorf("--merge requires --from or --to")
+			}
+			changes, err = printChanges(changelogFile, format, extractArgs.version, extractArgs.includeHeader)
+		}
 		if err != nil {
 			return err
 		}
@@ -55,10 +88,27 @@ func init() {
 
 	extractCmd.Flags().StringVarP(&extractArgs.version, "version", "v", "", "Version to parse changelog for")
 	extractCmd.Flags().BoolVar(&extractArgs.includeHeader, "header", false, "whether to include the version header in the output")
+	extractCmd.Flags().StringVar(&extractArgs.from, "from", "", "Extract all versions from this version")
+	extractCmd.Flags().StringVar(&extractArgs.to, "to", "", "Extract all versions up to this version")
+	extractCmd.Flags().BoolVar(&extractArgs.fromExclusive, "from-exclusive", false, "Exclude the --from version from the range")
+	extractCmd.Flags().BoolVar(&extractArgs.toExclusive, "to-exclusive", false, "Exclude the --to version from the range")
+	extractCmd.Flags().BoolVar(&extractArgs.merge, "merge", false, "Combine the same-named sections of all versions in the range")
+}
+
+func printChanges(changelogFile string, format changelog.Format, version string, includeHeader bool) (string, error) {
+	changes, err := changelog.ParseChangelog(changelogFile, format, version, includeHeader)
+	if err != nil {
+		return "", err
+	}
+	output := ""
+	for _, entry := range changes {
+		output += entry + "\n"
+	}
+	return output, nil
 }
 
-func printChanges(changelogFile string, version string, includeHeader bool) (string, error) {
-	changes, err := changelog.ParseChangelog(changelogFile, version, includeHeader)
+func printChangesInRange(changelogFile string, format changelog.Format, versionRange changelog.VersionRange, includeHeader bool, merge bool) (string, error) {
+	changes, err := changelog.ParseChangelogRange(changelogFile, format, versionRange, includeHeader, merge)
 	if err != nil {
 		return "", err
 	}
@@ -17,7 +17,11 @@ limitations under the License.
 package cmd
 
 import (
+	"github.com/release-tools/since/changelog"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -73,7 +77,7 @@ func Test_printChanges(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := printChanges(tt.args.path, tt.args.version, tt.args.includeHeader)
+			got, err := printChanges(tt.args.path, changelog.FormatMarkdown, tt.args.version, tt.args.includeHeader)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseChangelog() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -84,3 +88,129 @@ func Test_printChanges(t *testing.T) {
 		})
 	}
 }
+
+func Test_printChangesInRange(t *testing.T) {
+	type args struct {
+		versionRange  changelog.VersionRange
+		includeHeader bool
+		merge         bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "inclusive range",
+			args: args{versionRange: changelog.VersionRange{From: "1.1.0", To: "1.2.0"}, includeHeader: true},
+			want: `## [1.2.0] - 2023-03-07
+### Fixed
+- fix: second fix.
+
+## [1.1.0] - 2023-03-06
+### Added
+- feat: second feature.
+`,
+		},
+		{
+			name: "exclusive from, open to",
+			args: args{versionRange: changelog.VersionRange{From: "1.1.0", FromExclusive: true}, includeHeader: true},
+			want: `## [1.3.0] - 2023-03-08
+### Added
+- feat: third feature.
+
+### Fixed
+- fix: third fix.
+
+## [1.2.0] - 2023-03-07
+### Fixed
+- fix: second fix.
+`,
+		},
+		{
+			name: "exclusive to",
+			args: args{versionRange: changelog.VersionRange{From: "1.0.0", To: "1.1.0", ToExclusive: true}},
+			want: `### Added
+- feat: first feature.
+`,
+		},
+		{
+			name: "merge sections",
+			args: args{versionRange: changelog.VersionRange{From: "1.1.0"}, merge: true},
+			want: `### Added
+- feat: third feature.
+- feat: second feature.
+
+### Fixed
+- fix: third fix.
+- fix: second fix.
+`,
+		},
+		{
+			name:    "empty range",
+			args:    args{versionRange: changelog.VersionRange{From: "2.0.0"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			args:    args{versionRange: changelog.VersionRange{From: "latest"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := printChangesInRange("testdata/multiple.md", changelog.FormatMarkdown, tt.args.versionRange, tt.args.includeHeader, tt.args.merge)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("printChangesInRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("printChangesInRange() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveChangelogFormat_configured(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "since.yaml"), []byte("changelog:\n  format: asciidoc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the file name gives no hint of the format
+	changelogFile := filepath.Join(dir, "NEWS.txt")
+	content := "= Changelog\n\n== [1.0.0] - 2024-01-01\n=== Added\n* First feature\n"
+	if err := os.WriteFile(changelogFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	format, err := resolveChangelogFormat(dir, changelogFile)
+	if err != nil {
+		t.Fatalf("resolveChangelogFormat() error = %v", err)
+	}
+	if format != changelog.FormatAsciiDoc {
+		t.Fatalf("resolveChangelogFormat() = %v, want %v", format, changelog.FormatAsciiDoc)
+	}
+
+	changes, err := printChanges(changelogFile, format, "1.0.0", false)
+	if err != nil {
+		t.Fatalf("printChanges() error = %v", err)
+	}
+	if !strings.Contains(changes, "* First feature") {
+		t.Errorf("printChanges() = %q, want the 1.0.0 entries", changes)
+	}
+
+	exported, err := exportChangelog(changelogFile, format, changelog.ExportFormatJSON, "", changelog.VersionRange{})
+	if err != nil {
+		t.Fatalf("exportChangelog() error = %v", err)
+	}
+	if !strings.Contains(exported, `"version": "1.0.0"`) {
+		t.Errorf("exportChangelog() = %s, want version 1.0.0", exported)
+	}
+
+	changelogArgs.outputFile = filepath.Join(t.TempDir(), "lint.txt")
+	defer func() { changelogArgs.outputFile = "" }()
+	if err := lintChangelog(changelogFile, format, "text", nil, false); err != nil {
+		t.Errorf("lintChangelog() error = %v, want nil", err)
+	}
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"github.com/release-tools/since/cfg"
 	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/hooks"
 	"github.com/release-tools/since/vcs"
 	"github.com/spf13/cobra"
 )
 
 var generateArgs struct {
+	format   string
 	orderBy  string
 	repoPath string
 	unique   bool
@@ -44,15 +47,23 @@ then prints it to stdout, or output-file, if specified.`,
 			generateArgs.repoPath,
 			changelogArgs.changelogFile,
 		)
+		dates, err := newDateFilter(changelogArgs.sinceDate, changelogArgs.untilDate, changelogArgs.dateType)
+		if err != nil {
+			return err
+		}
 		commitCfg := vcs.CommitConfig{
 			ExcludeTagCommits: changelogArgs.excludeTagCommits,
 			UniqueOnly:        generateArgs.unique,
+			SkipCherryPicks:   changelogArgs.skipCherryPicks,
+			UseCache:          changelogArgs.cache,
+			Dates:             dates,
 		}
 		return generateChangelog(
 			commitCfg,
 			changelogFile,
 			vcs.TagOrderBy(generateArgs.orderBy),
 			generateArgs.repoPath,
+			generateArgs.format,
 		)
 	},
 }
@@ -63,6 +74,7 @@ func init() {
 	generateCmd.Flags().StringVarP(&generateArgs.orderBy, "order-by", "o", string(vcs.TagOrderSemver), "How to determine the latest tag (alphabetical|commit-date|semver))")
 	generateCmd.Flags().StringVarP(&generateArgs.repoPath, "git-repo", "g", ".", "Path to git repository")
 	generateCmd.Flags().BoolVar(&generateArgs.unique, "unique", true, "De-duplicate commit messages")
+	generateCmd.Flags().StringVarP(&generateArgs.format, "format", "f", "", "Changelog format (markdown|asciidoc|rst|debian|rpm), detected from the file name if not set")
 }
 
 func generateChangelog(
@@ -70,8 +82,9 @@ func generateChangelog(
 	changelogFile string,
 	orderBy vcs.TagOrderBy,
 	repoPath string,
+	format string,
 ) error {
-	config, err := cfg.LoadConfig(repoPath)
+	config, err := loadChangelogConfig(repoPath, format)
 	if err != nil {
 		return err
 	}
@@ -81,9 +94,21 @@ func generateChangelog(
 		return err
 	}
 
-	_, updated, err := changelog.GetUpdatedChangelog(config, commitCfg, changelogFile, orderBy, repoPath, "", latestTag)
+	metadata, updated, err := changelog.GetUpdatedChangelog(config, commitCfg, changelogFile, orderBy, repoPath, "", latestTag)
 	if err != nil {
 		return err
 	}
-	return writeOutput(updated)
+
+	if err := hooks.ExecuteCommandHooks(config, cfg.CommandChangelogGenerate, hooks.Before, metadata); err != nil {
+		return fmt.Errorf("failed to execute hooks before changelog generation: %w", err)
+	}
+
+	if err := writeOutput(updated); err != nil {
+		return err
+	}
+
+	if err := hooks.ExecuteCommandHooks(config, cfg.CommandChangelogGenerate, hooks.After, metadata); err != nil {
+		return fmt.Errorf("failed to execute hooks after changelog generation: %w", err)
+	}
+	return nil
 }
@@ -34,7 +34,7 @@ func Test_generateChangelog(t *testing.T) {
 		defer func() { changelogArgs.outputFile = "" }()
 
 		commitCfg := vcs.CommitConfig{UniqueOnly: true}
-		err := generateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir)
+		err := generateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir, "")
 		if err != nil {
 			t.Fatalf("generateChangelog() error = %v", err)
 		}
@@ -64,7 +64,7 @@ func Test_generateChangelog(t *testing.T) {
 		defer func() { changelogArgs.outputFile = "" }()
 
 		commitCfg := vcs.CommitConfig{UniqueOnly: true}
-		if err := generateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir); err != nil {
+		if err := generateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir, ""); err != nil {
 			t.Fatalf("generateChangelog() error = %v", err)
 		}
 
@@ -79,7 +79,7 @@ func Test_generateChangelog(t *testing.T) {
 
 	t.Run("returns error for a non-repository path", func(t *testing.T) {
 		commitCfg := vcs.CommitConfig{UniqueOnly: true}
-		err := generateChangelog(commitCfg, "CHANGELOG.md", vcs.TagOrderSemver, t.TempDir())
+		err := generateChangelog(commitCfg, "CHANGELOG.md", vcs.TagOrderSemver, t.TempDir(), "")
 		if err == nil {
 			t.Error("generateChangelog() expected error for a non-repository path")
 		}
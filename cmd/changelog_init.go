@@ -17,7 +17,6 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/release-tools/since/cfg"
 	"github.com/release-tools/since/changelog"
 	"github.com/release-tools/since/vcs"
 	"github.com/sirupsen/logrus"
@@ -25,6 +24,7 @@ import (
 )
 
 var initArgs struct {
+	format   string
 	orderBy  string
 	repoPath string
 	unique   bool
@@ -43,15 +43,23 @@ var initCmd = &cobra.Command{
 			initArgs.repoPath,
 			changelogArgs.changelogFile,
 		)
+		dates, err := newDateFilter(changelogArgs.sinceDate, changelogArgs.untilDate, changelogArgs.dateType)
+		if err != nil {
+			return err
+		}
 		commitCfg := vcs.CommitConfig{
 			ExcludeTagCommits: changelogArgs.excludeTagCommits,
 			UniqueOnly:        initArgs.unique,
+			SkipCherryPicks:   changelogArgs.skipCherryPicks,
+			UseCache:          changelogArgs.cache,
+			Dates:             dates,
 		}
 		return initChangelog(
 			commitCfg,
 			changelogFile,
 			vcs.TagOrderBy(initArgs.orderBy),
 			initArgs.repoPath,
+			initArgs.format,
 		)
 	},
 }
@@ -62,10 +70,11 @@ func init() {
 	initCmd.Flags().StringVarP(&initArgs.orderBy, "order-by", "o", string(vcs.TagOrderSemver), "How to determine the latest tag (alphabetical|commit-date|semver))")
 	initCmd.Flags().StringVarP(&initArgs.repoPath, "git-repo", "g", ".", "Path to git repository")
 	initCmd.Flags().BoolVar(&initArgs.unique, "unique", true, "De-duplicate commit messages")
+	initCmd.Flags().StringVarP(&initArgs.format, "format", "f", "", "Changelog format (markdown|asciidoc|rst|debian|rpm), detected from the file name if not set")
 }
 
-func initChangelog(commitCfg vcs.CommitConfig, changelogFile string, orderBy vcs.TagOrderBy, repoPath string) error {
-	config, err := cfg.LoadConfig(repoPath)
+func initChangelog(commitCfg vcs.CommitConfig, changelogFile string, orderBy vcs.TagOrderBy, repoPath string, format string) error {
+	config, err := loadChangelogConfig(repoPath, format)
 	if err != nil {
 		return err
 	}
@@ -35,7 +35,7 @@ func Test_initChangelog(t *testing.T) {
 
 		commitCfg := vcs.CommitConfig{UniqueOnly: true}
 		// pass a changelog file that does not yet exist, as init would in practice
-		err := initChangelog(commitCfg, filepath.Join(repoDir, "CHANGELOG.md"), vcs.TagOrderSemver, repoDir)
+		err := initChangelog(commitCfg, filepath.Join(repoDir, "CHANGELOG.md"), vcs.TagOrderSemver, repoDir, "")
 		if err != nil {
 			t.Fatalf("initChangelog() error = %v", err)
 		}
@@ -62,7 +62,7 @@ func Test_initChangelog(t *testing.T) {
 		// use an absolute path within a temp dir: InitChangelog writes the
 		// template before hitting the repo error, so a relative path would
 		// otherwise pollute the working directory.
-		err := initChangelog(commitCfg, filepath.Join(tmpDir, "CHANGELOG.md"), vcs.TagOrderSemver, tmpDir)
+		err := initChangelog(commitCfg, filepath.Join(tmpDir, "CHANGELOG.md"), vcs.TagOrderSemver, tmpDir, "")
 		if err == nil {
 			t.Error("initChangelog() expected error for a non-repository path")
 		}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/release-tools/since/changelog"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"path/filepath"
+)
+
+var lintArgs struct {
+	allowSections []string
+	format        string
+	strict        bool
+}
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check that the changelog is well-formed",
+	Long: `Checks that a changelog file follows the Keep a Changelog format,
+with semantic version headings in descending order, valid ISO dates,
+no duplicate versions, no empty or unknown sections, and no dangling
+link references.
+
+Prints any problems found to stdout, or output-file, if specified,
+and exits with a non-zero status if there are errors, or warnings
+when --strict is set.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workingDir, err := getWorkingDir()
+		if err != nil {
+			return err
+		}
+		changelogFile := changelog.ResolveChangelogFile(workingDir, changelogArgs.changelogFile)
+		changelogFormat, err := resolveChangelogFormat(workingDir, changelogFile)
+		if err != nil {
+			return err
+		}
+		return lintChangelog(changelogFile, changelogFormat, lintArgs.format, lintArgs.allowSections, lintArgs.strict)
+	},
+}
+
+func init() {
+	changelogCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintArgs.format, "format", "f", "text", "Output format (text|json)")
+	lintCmd.Flags().StringSliceVar(&lintArgs.allowSections, "allow-section", nil, "Additional section names to accept")
+	lintCmd.Flags().BoolVar(&lintArgs.strict, "strict", false, "Treat warnings as errors")
+}
+
+func lintChangelog(changelogFile string, changelogFormat changelog.Format, format string, allowSections []string, strict bool) error {
+	doc, err := changelog.ReadDocument(changelogFile, changelogFormat)
+	if err != nil {
+		return err
+	}
+	issues := changelog.Lint(doc, allowSections)
+
+	output, err := formatLintIssues(filepath.Base(changelogFile), issues, format)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 || format == "json" {
+		if err := writeOutput(output); err != nil {
+			return err
+		}
+	}
+
+	var errors, warnings int
+	for _, issue := range issues {
+		if issue.Severity == changelog.LintError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	if errors > 0 || (strict && warnings > 0) {
+		return fmt.Errorf("changelog has %d error(s) and %d warning(s)", errors, warnings)
+	}
+	logrus.Infof("changelog has %d error(s) and %d warning(s)", errors, warnings)
+	return nil
+}
+
+// formatLintIssues renders the issues as text or JSON.
+func formatLintIssues(fileName string, issues []changelog.LintIssue, format string) (string, error) {
+	switch format {
+	case "text":
+		output := ""
+		for i, issue := range issues {
+			if i > 0 {
+				output += "\n"
+			}
+			output += fmt.Sprintf("%s:%d: %s: %s (%s)", fileName, issue.Line, issue.Severity, issue.Message, issue.Rule)
+		}
+		return output, nil
+
+	case "json":
+		if issues == nil {
+			issues = []changelog.LintIssue{}
+		}
+		out, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal lint issues to JSON: %w", err)
+		}
+		return string(out), nil
+
+	default:
+		return "", fmt.Errorf("unsupported output format: %s", format)
+	}
+}
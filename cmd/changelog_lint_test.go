@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/release-tools/since/changelog"
+)
+
+func Test_lintChangelog(t *testing.T) {
+	tests := []struct {
+		name          string
+		changelogFile string
+		format        string
+		strict        bool
+		wantOutput    []string
+		wantErr       bool
+	}{
+		{
+			name:          "valid changelog",
+			changelogFile: "testdata/multiple.md",
+			format:        "text",
+		},
+		{
+			name:          "valid changelog as json",
+			changelogFile: "testdata/multiple.md",
+			format:        "json",
+			wantOutput:    []string{"[]"},
+		},
+		{
+			name:          "invalid changelog",
+			changelogFile: "testdata/invalid.md",
+			format:        "text",
+			wantOutput: []string{
+				"invalid.md:7: error: version 1.1.0 should be listed before 1.0.0 (version-order)",
+				"invalid.md:8: warning: unknown section name 'Misc'",
+				"invalid.md:11: error: Unreleased section should be the first section (unreleased-position)",
+				"invalid.md:12: warning: section 'Fixed' of Unreleased has no entries (empty-section)",
+				"invalid.md:14: error: version 1.0.0 is already listed on line 3 (duplicate-version)",
+				"invalid.md:14: error: '5 March 2023' is not a valid ISO 8601 date (YYYY-MM-DD) (invalid-date)",
+				"invalid.md:19: warning: link reference '2.0.0' does not match any version (dangling-link)",
+			},
+			wantErr: true,
+		},
+		{
+			name:          "invalid changelog as json",
+			changelogFile: "testdata/invalid.md",
+			format:        "json",
+			wantOutput:    []string{`"rule": "dangling-link"`, `"severity": "warning"`, `"line": 19`},
+			wantErr:       true,
+		},
+		{
+			name:          "unsupported format",
+			changelogFile: "testdata/multiple.md",
+			format:        "xml",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outPath := filepath.Join(t.TempDir(), "lint.txt")
+			changelogArgs.outputFile = outPath
+			defer func() { changelogArgs.outputFile = "" }()
+
+			err := lintChangelog(tt.changelogFile, changelog.FormatMarkdown, tt.format, nil, tt.strict)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lintChangelog() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			output, _ := os.ReadFile(outPath)
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(string(output), want) {
+					t.Errorf("lintChangelog() output missing %q, got:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
+
+func Test_lintChangelog_strict(t *testing.T) {
+	changelogFile := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	err := os.WriteFile(changelogFile, []byte("## [1.0.0] - 2023-03-05\n### Added\n- feat: a.\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changelogArgs.outputFile = filepath.Join(t.TempDir(), "lint.txt")
+	defer func() { changelogArgs.outputFile = "" }()
+
+	// a missing title is only a warning
+	if err := lintChangelog(changelogFile, changelog.FormatMarkdown, "text", nil, false); err != nil {
+		t.Errorf("lintChangelog() error = %v, want nil", err)
+	}
+	if err := lintChangelog(changelogFile, changelog.FormatMarkdown, "text", nil, true); err == nil {
+		t.Errorf("lintChangelog() with strict error = nil, want error")
+	}
+}
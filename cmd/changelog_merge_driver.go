@@ -0,0 +1,91 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/vcs"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// mergeDriverCmd represents the merge-driver command
+var mergeDriverCmd = &cobra.Command{
+	Use:   "merge-driver <base> <ours> <theirs>",
+	Short: "Merge changes to a changelog, as a git merge driver",
+	Long: `Performs a three-way merge of a Markdown changelog file, for use as
+a git custom merge driver. The entries added on each side are combined
+per release and section, and the result is written to the <ours> file.
+
+If both sides changed the same part of the changelog differently, such
+as the date of a release, the conflicts are listed, the file is merged
+line by line with conflict markers, and the command fails.
+
+To use it for CHANGELOG.md, add to .gitattributes:
+
+  CHANGELOG.md merge=since-changelog
+
+and configure the driver:
+
+  git config merge.since-changelog.name "since changelog merge driver"
+  git config merge.since-changelog.driver "since changelog merge-driver %O %A %B"`,
+	Args:          cobra.ExactArgs(3),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return mergeChangelogFiles(args[0], args[1], args[2])
+	},
+}
+
+func init() {
+	changelogCmd.AddCommand(mergeDriverCmd)
+}
+
+func mergeChangelogFiles(baseFile string, oursFile string, theirsFile string) error {
+	var contents []string
+	for _, file := range []string{baseFile, oursFile, theirsFile} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read changelog: %w", err)
+		}
+		contents = append(contents, string(content))
+	}
+
+	merged, err := changelog.MergeChangelogs(contents[0], contents[1], contents[2])
+	if err != nil {
+		var conflictErr *changelog.MergeConflictError
+		if !errors.As(err, &conflictErr) {
+			return err
+		}
+		for _, conflict := range conflictErr.Conflicts {
+			logrus.Errorf("conflict: %s", conflict)
+		}
+		if _, mergeErr := vcs.MergeFile(oursFile, baseFile, theirsFile); mergeErr != nil {
+			logrus.Warnf("failed to write conflict markers: %v", mergeErr)
+		}
+		return err
+	}
+
+	if err := os.WriteFile(oursFile, []byte(merged), 0644); err != nil {
+		return fmt.Errorf("failed to write merged changelog: %w", err)
+	}
+	logrus.Debugf("merged changelog into %s", oursFile)
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_mergeChangelogFiles(t *testing.T) {
+	writeFiles := func(t *testing.T, base string, ours string, theirs string) (string, string, string) {
+		dir := t.TempDir()
+		for name, content := range map[string]string{"base": base, "ours": ours, "theirs": theirs} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return filepath.Join(dir, "base"), filepath.Join(dir, "ours"), filepath.Join(dir, "theirs")
+	}
+
+	t.Run("writes the merged changelog to the ours file", func(t *testing.T) {
+		baseFile, oursFile, theirsFile := writeFiles(t,
+			"# Changelog\n\n## [Unreleased]\n### Added\n- feat: one\n",
+			"# Changelog\n\n## [Unreleased]\n### Added\n- feat: one\n- feat: two\n",
+			"# Changelog\n\n## [Unreleased]\n### Added\n- feat: one\n- feat: three\n",
+		)
+		if err := mergeChangelogFiles(baseFile, oursFile, theirsFile); err != nil {
+			t.Fatalf("mergeChangelogFiles() error = %v", err)
+		}
+		content, err := os.ReadFile(oursFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "# Changelog\n\n## [Unreleased]\n### Added\n- feat: one\n- feat: two\n- feat: three\n"
+		if string(content) != want {
+			t.Errorf("merged changelog = %q, want %q", string(content), want)
+		}
+	})
+
+	t.Run("fails and writes conflict markers on a conflict", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not available")
+		}
+		baseFile, oursFile, theirsFile := writeFiles(t,
+			"# Changelog\n",
+			"# Changelog\n\n## [1.1.0] - 2024-02-01\n- one\n",
+			"# Changelog\n\n## [1.1.0] - 2024-02-02\n- one\n",
+		)
+		err := mergeChangelogFiles(baseFile, oursFile, theirsFile)
+		if err == nil || !strings.Contains(err.Error(), "version 1.1.0 has different dates") {
+			t.Fatalf("mergeChangelogFiles() error = %v, want conflict", err)
+		}
+		content, err := os.ReadFile(oursFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "<<<<<<< ours") || !strings.Contains(string(content), ">>>>>>> theirs") {
+			t.Errorf("changelog does not contain conflict markers:\n%s", string(content))
+		}
+	})
+
+	t.Run("returns error for a missing file", func(t *testing.T) {
+		dir := t.TempDir()
+		err := mergeChangelogFiles(filepath.Join(dir, "base"), filepath.Join(dir, "ours"), filepath.Join(dir, "theirs"))
+		if err == nil {
+			t.Error("mergeChangelogFiles() expected error for a missing file")
+		}
+	})
+}
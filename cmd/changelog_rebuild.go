@@ -0,0 +1,105 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/vcs"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var rebuildArgs struct {
+	format       string
+	from         string
+	to           string
+	keepExisting bool
+	repoPath     string
+	unique       bool
+}
+
+// rebuildCmd represents the rebuild command
+var rebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Regenerate the changelog from the tag history",
+	Long: `Regenerates the release sections of the changelog file from the
+tags in the git repository, then writes the full changelog file.
+
+Use --from and/or --to to only regenerate the versions in a range.
+With --keep-existing, versions that are already in the changelog are
+kept as they are, and only missing versions are generated.
+
+The preamble, the Unreleased section and any link references are kept.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		changelogFile := changelog.ResolveChangelogFile(rebuildArgs.repoPath, changelogArgs.changelogFile)
+		dates, err := newDateFilter(changelogArgs.sinceDate, changelogArgs.untilDate, changelogArgs.dateType)
+		if err != nil {
+			return err
+		}
+		commitCfg := vcs.CommitConfig{
+			ExcludeTagCommits: changelogArgs.excludeTagCommits,
+			UniqueOnly:        rebuildArgs.unique,
+			SkipCherryPicks:   changelogArgs.skipCherryPicks,
+			UseCache:          changelogArgs.cache,
+			Dates:             dates,
+		}
+		versionRange := changelog.VersionRange{
+			From: rebuildArgs.from,
+			To:   rebuildArgs.to,
+		}
+		return rebuildChangelog(commitCfg, changelogFile, rebuildArgs.repoPath, rebuildArgs.format, versionRange, rebuildArgs.keepExisting)
+	},
+}
+
+func init() {
+	changelogCmd.AddCommand(rebuildCmd)
+
+	rebuildCmd.Flags().StringVarP(&rebuildArgs.format, "format", "f", "", "Changelog format (markdown|asciidoc|rst|debian|rpm), detected from the file name if not set")
+	rebuildCmd.Flags().StringVar(&rebuildArgs.from, "from", "", "Regenerate versions from this version")
+	rebuildCmd.Flags().StringVar(&rebuildArgs.to, "to", "", "Regenerate versions up to this version")
+	rebuildCmd.Flags().BoolVar(&rebuildArgs.keepExisting, "keep-existing", false, "Keep the sections of versions already in the changelog")
+	rebuildCmd.Flags().StringVarP(&rebuildArgs.repoPath, "git-repo", "g", ".", "Path to git repository")
+	rebuildCmd.Flags().BoolVar(&rebuildArgs.unique, "unique", true, "De-duplicate commit messages")
+}
+
+func rebuildChangelog(
+	commitCfg vcs.CommitConfig,
+	changelogFile string,
+	repoPath string,
+	format string,
+	versionRange changelog.VersionRange,
+	keepExisting bool,
+) error {
+	config, err := loadChangelogConfig(repoPath, format)
+	if err != nil {
+		return err
+	}
+
+	rebuilt, err := changelog.RebuildChangelog(config, commitCfg, changelogFile, repoPath, versionRange, keepExisting)
+	if err != nil {
+		return err
+	}
+	if err := changelog.WriteChangelog(changelogFile, rebuilt); err != nil {
+		return fmt.Errorf("failed to write rebuilt changelog: %w", err)
+	}
+	logrus.Infof("rebuilt changelog file '%s'", changelogFile)
+	return nil
+}
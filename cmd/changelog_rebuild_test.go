@@ -0,0 +1,73 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/vcs"
+)
+
+func Test_rebuildChangelog(t *testing.T) {
+	t.Run("regenerates the tagged releases in the changelog file", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+
+		commitCfg := vcs.CommitConfig{UniqueOnly: true}
+		err := rebuildChangelog(commitCfg, changelogFile, repoDir, "", changelog.VersionRange{}, false)
+		if err != nil {
+			t.Fatalf("rebuildChangelog() error = %v", err)
+		}
+
+		content, err := os.ReadFile(changelogFile)
+		if err != nil {
+			t.Fatalf("failed to read rebuilt changelog: %v", err)
+		}
+		got := string(content)
+		if !strings.Contains(got, "# Change Log") {
+			t.Errorf("rebuilt changelog missing existing boilerplate:\n%s", got)
+		}
+		if !strings.Contains(got, "## [0.1.0] - 2023-11-14\n### Changed\n- chore: initial commit") {
+			t.Errorf("rebuilt changelog missing the regenerated release section:\n%s", got)
+		}
+		if strings.Contains(got, "shiny new feature") {
+			t.Errorf("rebuilt changelog should not include unreleased commits:\n%s", got)
+		}
+	})
+
+	t.Run("returns error for an invalid version range", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+
+		commitCfg := vcs.CommitConfig{UniqueOnly: true}
+		err := rebuildChangelog(commitCfg, changelogFile, repoDir, "", changelog.VersionRange{From: "latest"}, false)
+		if err == nil {
+			t.Error("rebuildChangelog() expected error for an invalid version range")
+		}
+	})
+
+	t.Run("returns error for a non-repository path", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		commitCfg := vcs.CommitConfig{UniqueOnly: true}
+		err := rebuildChangelog(commitCfg, filepath.Join(tmpDir, "CHANGELOG.md"), tmpDir, "", changelog.VersionRange{}, false)
+		if err == nil {
+			t.Error("rebuildChangelog() expected error for a non-repository path")
+		}
+	})
+}
@@ -143,3 +143,29 @@ This project adheres to [Semantic Versioning](http://semver.org/).
 	}
 	return repoDir, changelogFile
 }
+
+// addTestCommit commits a change to the README of the repository with
+// the given message, after the commits of createChangelogTestRepo.
+func addTestCommit(t *testing.T, repoDir string, message string, offsetMillis int64) plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte(message), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("README.md"); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "user", Email: "user@example.com", When: time.UnixMilli(baseTimeMillis + offsetMillis)}
+	h, err := w.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
@@ -20,11 +20,13 @@ import (
 	"fmt"
 	"github.com/release-tools/since/cfg"
 	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/hooks"
 	"github.com/release-tools/since/vcs"
 	"github.com/spf13/cobra"
 )
 
 var updateArgs struct {
+	format   string
 	orderBy  string
 	repoPath string
 	unique   bool
@@ -35,21 +37,30 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Write updated changelog based on changes since last release",
 	Long: `Updates the existing changelog file with a new release section,
-using the commits since the last release.`,
+using the commits since the last release and any changelog
+fragments, which are removed once they are in the changelog.`,
 	Args:          cobra.NoArgs,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		changelogFile := changelog.ResolveChangelogFile(updateArgs.repoPath, changelogArgs.changelogFile)
+		dates, err := newDateFilter(changelogArgs.sinceDate, changelogArgs.untilDate, changelogArgs.dateType)
+		if err != nil {
+			return err
+		}
 		commitCfg := vcs.CommitConfig{
 			ExcludeTagCommits: changelogArgs.excludeTagCommits,
 			UniqueOnly:        updateArgs.unique,
+			SkipCherryPicks:   changelogArgs.skipCherryPicks,
+			UseCache:          changelogArgs.cache,
+			Dates:             dates,
 		}
 		return updateChangelog(
 			commitCfg,
 			changelogFile,
 			vcs.TagOrderBy(updateArgs.orderBy),
 			updateArgs.repoPath,
+			updateArgs.format,
 		)
 	},
 }
@@ -60,6 +71,7 @@ func init() {
 	updateCmd.Flags().StringVarP(&updateArgs.orderBy, "order-by", "o", string(vcs.TagOrderSemver), "How to determine the latest tag (alphabetical|commit-date|semver))")
 	updateCmd.Flags().StringVarP(&updateArgs.repoPath, "git-repo", "g", ".", "Path to git repository")
 	updateCmd.Flags().BoolVar(&updateArgs.unique, "unique", true, "De-duplicate commit messages")
+	updateCmd.Flags().StringVarP(&updateArgs.format, "format", "f", "", "Changelog format (markdown|asciidoc|rst|debian|rpm), detected from the file name if not set")
 }
 
 func updateChangelog(
@@ -67,8 +79,9 @@ func updateChangelog(
 	changelogFile string,
 	orderBy vcs.TagOrderBy,
 	repoPath string,
+	format string,
 ) error {
-	config, err := cfg.LoadConfig(repoPath)
+	config, err := loadChangelogConfig(repoPath, format)
 	if err != nil {
 		return err
 	}
@@ -78,13 +91,24 @@ func updateChangelog(
 		return err
 	}
 
-	_, updated, err := changelog.GetUpdatedChangelog(config, commitCfg, changelogFile, orderBy, repoPath, "", latestTag)
+	metadata, updated, err := changelog.GetUpdatedChangelog(config, commitCfg, changelogFile, orderBy, repoPath, "", latestTag)
 	if err != nil {
 		return err
 	}
 
+	if err := hooks.ExecuteCommandHooks(config, cfg.CommandChangelogUpdate, hooks.Before, metadata); err != nil {
+		return fmt.Errorf("failed to execute hooks before changelog update: %w", err)
+	}
+
 	if err := changelog.WriteChangelog(changelogFile, updated); err != nil {
 		return fmt.Errorf("failed to update changelog: %w", err)
 	}
+	if err := changelog.RemoveFragments(metadata.Fragments); err != nil {
+		return err
+	}
+
+	if err := hooks.ExecuteCommandHooks(config, cfg.CommandChangelogUpdate, hooks.After, metadata); err != nil {
+		return fmt.Errorf("failed to execute hooks after changelog update: %w", err)
+	}
 	return nil
 }
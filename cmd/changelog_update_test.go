@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -29,7 +30,7 @@ func Test_updateChangelog(t *testing.T) {
 		repoDir, changelogFile := createChangelogTestRepo(t)
 		commitCfg := vcs.CommitConfig{UniqueOnly: true}
 
-		err := updateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir)
+		err := updateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir, "")
 		if err != nil {
 			t.Fatalf("updateChangelog() error = %v", err)
 		}
@@ -53,9 +54,90 @@ func Test_updateChangelog(t *testing.T) {
 		}
 	})
 
+	t.Run("runs hooks attached to the command", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+		commitCfg := vcs.CommitConfig{UniqueOnly: true}
+
+		config := `commands:
+  changelog update:
+    after:
+      - script: echo "{{.NewVersion}}" > docs-version.txt
+`
+		if err := os.WriteFile(filepath.Join(repoDir, "since.yaml"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := updateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir, ""); err != nil {
+			t.Fatalf("updateChangelog() error = %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(repoDir, "docs-version.txt"))
+		if err != nil {
+			t.Fatalf("after hook did not run: %v", err)
+		}
+		if strings.TrimSpace(string(content)) != "0.2.0" {
+			t.Errorf("after hook wrote %q, want 0.2.0", string(content))
+		}
+	})
+
+	t.Run("includes and removes changelog fragments", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+		commitCfg := vcs.CommitConfig{UniqueOnly: true}
+
+		fragment := filepath.Join(repoDir, ".changes", "breaking.md")
+		if err := os.MkdirAll(filepath.Dir(fragment), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fragment, []byte("---\nbump: major\n---\nRemoves the legacy API.\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := updateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir, ""); err != nil {
+			t.Fatalf("updateChangelog() error = %v", err)
+		}
+
+		content, err := os.ReadFile(changelogFile)
+		if err != nil {
+			t.Fatalf("failed to read changelog file: %v", err)
+		}
+		got := string(content)
+		if !strings.Contains(got, "## [1.0.0]") {
+			t.Errorf("changelog does not contain new 1.0.0 section:\n%s", got)
+		}
+		if !strings.Contains(got, "- Removes the legacy API.") {
+			t.Errorf("changelog does not contain the fragment entry:\n%s", got)
+		}
+		if _, err := os.Stat(fragment); !os.IsNotExist(err) {
+			t.Errorf("fragment was not removed")
+		}
+	})
+
+	t.Run("releases a breaking change footer as a major version", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+		commitCfg := vcs.CommitConfig{UniqueOnly: true}
+		// the footer is the only sign of the breaking change
+		addTestCommit(t, repoDir, "feat: read YAML config\n\nBREAKING CHANGE: the config file is now YAML", 20000)
+
+		if err := updateChangelog(commitCfg, changelogFile, vcs.TagOrderSemver, repoDir, ""); err != nil {
+			t.Fatalf("updateChangelog() error = %v", err)
+		}
+
+		content, err := os.ReadFile(changelogFile)
+		if err != nil {
+			t.Fatalf("failed to read changelog file: %v", err)
+		}
+		got := string(content)
+		if !strings.Contains(got, "## [1.0.0]") {
+			t.Errorf("changelog does not contain new 1.0.0 section:\n%s", got)
+		}
+		if !strings.Contains(got, "### ⚠ Breaking Changes") {
+			t.Errorf("changelog does not contain the breaking change:\n%s", got)
+		}
+	})
+
 	t.Run("returns error for a non-repository path", func(t *testing.T) {
 		commitCfg := vcs.CommitConfig{UniqueOnly: true}
-		err := updateChangelog(commitCfg, "CHANGELOG.md", vcs.TagOrderSemver, t.TempDir())
+		err := updateChangelog(commitCfg, "CHANGELOG.md", vcs.TagOrderSemver, t.TempDir(), "")
 		if err == nil {
 			t.Error("updateChangelog() expected error for a non-repository path")
 		}
@@ -22,8 +22,10 @@ import (
 )
 
 var projectArgs struct {
+	cache             bool
 	excludeTagCommits bool
 	orderBy           string
+	skipCherryPicks   bool
 	repoPath          string
 	tag               string
 }
@@ -44,4 +46,6 @@ func init() {
 	projectCmd.PersistentFlags().StringVarP(&projectArgs.orderBy, "order-by", "o", string(vcs.TagOrderSemver), "How to determine the latest tag (alphabetical|commit-date|semver))")
 	projectCmd.PersistentFlags().StringVarP(&projectArgs.repoPath, "git-repo", "g", ".", "Path to git repository")
 	projectCmd.PersistentFlags().StringVarP(&projectArgs.tag, "tag", "t", "", "Include commits after this tag")
+	projectCmd.PersistentFlags().BoolVar(&projectArgs.skipCherryPicks, "skip-cherry-picks", false, "Skip commits that copy a commit already released under another tag")
+	projectCmd.PersistentFlags().BoolVar(&projectArgs.cache, "cache", false, "Cache the parsed history in the git directory, to speed up later runs")
 }
@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"github.com/release-tools/since/cfg"
 	"github.com/release-tools/since/changelog"
+	"github.com/release-tools/since/semver"
 	"github.com/release-tools/since/vcs"
 	"github.com/spf13/cobra"
 )
 
 var changesArgs struct {
-	unique bool
+	dateType  string
+	sinceDate string
+	unique    bool
+	untilDate string
 }
 
 // changesCmd represents the changes command
@@ -33,13 +37,25 @@ var changesCmd = &cobra.Command{
 	Use:   "changes",
 	Short: "List the changes since the last release",
 	Long: `Reads the commit history for the current git repository, starting
-from the most recent tag. Lists the commits categorised by their type.`,
+from the most recent tag. Lists the commits categorised by their type.
+
+Use --since-date and/or --until-date to only list the commits dated
+within a range. Unless --tag is also set, the range is not limited to
+the commits since the most recent tag, and the commits are grouped by
+the release that contains them.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		dates, err := newDateFilter(changesArgs.sinceDate, changesArgs.untilDate, changesArgs.dateType)
+		if err != nil {
+			return err
+		}
 		commitCfg := vcs.CommitConfig{
 			ExcludeTagCommits: projectArgs.excludeTagCommits,
 			UniqueOnly:        changesArgs.unique,
+			SkipCherryPicks:   projectArgs.skipCherryPicks,
+			UseCache:          projectArgs.cache,
+			Dates:             dates,
 		}
 		changes, err := listCommits(
 			commitCfg,
@@ -59,6 +75,7 @@ func init() {
 	projectCmd.AddCommand(changesCmd)
 
 	changesCmd.Flags().BoolVar(&changesArgs.unique, "unique", true, "De-duplicate commit messages")
+	addDateFlags(changesCmd.Flags(), &changesArgs.sinceDate, &changesArgs.untilDate, &changesArgs.dateType)
 }
 
 func listCommits(
@@ -72,20 +89,36 @@ func listCommits(
 		return "", err
 	}
 
-	var afterTag string
-	if tag == "" {
+	// with a date range and no tag, the dates alone define the range
+	afterTag := tag
+	if afterTag == "" && !commitCfg.Dates.IsSet() {
 		latestTag, err := vcs.GetLatestTag(repoPath, orderBy)
 		if err != nil {
 			return "", err
 		}
 		afterTag = latestTag
-	} else {
-		afterTag = tag
 	}
 
 	commits, _, err := vcs.FetchCommitsByTag(config, commitCfg, repoPath, "", afterTag)
 	if err != nil {
 		return "", err
 	}
-	return changelog.RenderCommits(commits, true, false, vcs.UnreleasedVersionName), nil
+	format, err := changelog.ResolveFormat(config, "")
+	if err != nil {
+		return "", err
+	}
+	renderer, err := changelog.NewRenderer(config, repoPath, format)
+	if err != nil {
+		return "", err
+	}
+	if format.IsPackage() && len(*commits) > 0 && (*commits)[0].Name == vcs.UnreleasedVersionName {
+		// package changelogs list unreleased changes under the next version
+		currentVersion, _, err := semver.GetCurrentVersion(repoPath, orderBy)
+		if err != nil {
+			return "", err
+		}
+		unreleased := (*commits)[0]
+		renderer.NextVersion = semver.GetNextVersionWithBump(currentVersion, false, unreleased.Commits, semver.BreakingChangeBump(unreleased.CommitDetails()))
+	}
+	return renderer.Render(commits, true, false, vcs.UnreleasedVersionName)
 }
@@ -19,6 +19,7 @@ package cmd
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/release-tools/since/vcs"
 )
@@ -50,6 +51,38 @@ func Test_listCommits(t *testing.T) {
 		}
 	})
 
+	t.Run("lists commits within a date range across releases", func(t *testing.T) {
+		repoDir, _ := createChangelogTestRepo(t)
+		between := time.UnixMilli(baseTimeMillis + 5000).UTC().Format(time.RFC3339)
+
+		for _, tt := range []struct {
+			since, until string
+			want, skip   string
+		}{
+			{since: between, want: "add a shiny new feature", skip: "initial commit"},
+			{until: between, want: "## [0.1.0]", skip: "add a shiny new feature"},
+		} {
+			dates, err := newDateFilter(tt.since, tt.until, "committer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			commitCfg := vcs.CommitConfig{UniqueOnly: true, Dates: dates}
+			got, err := listCommits(commitCfg, repoDir, "", vcs.TagOrderSemver)
+			if err != nil {
+				t.Fatalf("listCommits() error = %v", err)
+			}
+			if !strings.Contains(got, tt.want) || strings.Contains(got, tt.skip) {
+				t.Errorf("listCommits() since %q until %q = %s, want %q and not %q", tt.since, tt.until, got, tt.want, tt.skip)
+			}
+		}
+	})
+
+	t.Run("returns error for an invalid date type", func(t *testing.T) {
+		if _, err := newDateFilter("2024-07-01", "", "merged"); err == nil {
+			t.Error("newDateFilter() expected error for an invalid date type")
+		}
+	})
+
 	t.Run("returns error for a non-repository path", func(t *testing.T) {
 		commitCfg := vcs.CommitConfig{UniqueOnly: true}
 		if _, err := listCommits(commitCfg, t.TempDir(), "", vcs.TagOrderSemver); err == nil {
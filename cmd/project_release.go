@@ -22,11 +22,14 @@ import (
 	"github.com/release-tools/since/changelog"
 	"github.com/release-tools/since/hooks"
 	"github.com/release-tools/since/vcs"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var releaseArgs struct {
 	changelogFile string
+	push          bool
+	remote        string
 	unique        bool
 }
 
@@ -37,8 +40,10 @@ var releaseCmd = &cobra.Command{
 	Long: `Generates a new changelog based on an existing changelog file,
 using the commits since the last release.
 
-The changelog is then committed and a new tag is created
-with the new version.`,
+The changelog is then committed, removing any changelog
+fragments included in the release, and a new tag is created
+with the new version. If --push is set, the commit and tag
+are pushed to the remote.`,
 	Args:          cobra.NoArgs,
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -47,12 +52,16 @@ with the new version.`,
 		commitCfg := vcs.CommitConfig{
 			ExcludeTagCommits: projectArgs.excludeTagCommits,
 			UniqueOnly:        releaseArgs.unique,
+			SkipCherryPicks:   projectArgs.skipCherryPicks,
+			UseCache:          projectArgs.cache,
 		}
 		return release(
 			commitCfg,
 			changelogFile,
 			vcs.TagOrderBy(projectArgs.orderBy),
 			projectArgs.repoPath,
+			releaseArgs.push,
+			releaseArgs.remote,
 		)
 	},
 }
@@ -61,6 +70,8 @@ func init() {
 	projectCmd.AddCommand(releaseCmd)
 
 	releaseCmd.Flags().StringVarP(&releaseArgs.changelogFile, "changelog", "c", "CHANGELOG.md", "Path to changelog file")
+	releaseCmd.Flags().BoolVar(&releaseArgs.push, "push", false, "Push the release commit and tag to the remote")
+	releaseCmd.Flags().StringVar(&releaseArgs.remote, "remote", "origin", "Remote to push the release to")
 	releaseCmd.Flags().BoolVar(&releaseArgs.unique, "unique", true, "De-duplicate commit messages")
 }
 
@@ -69,6 +80,8 @@ func release(
 	changelogFile string,
 	orderBy vcs.TagOrderBy,
 	repoPath string,
+	push bool,
+	remote string,
 ) error {
 	config, err := cfg.LoadConfig(repoPath)
 	if err != nil {
@@ -88,30 +101,61 @@ func release(
 		return err
 	}
 
-	version := metadata.NewVersion
-	if metadata.VPrefix {
-		version = "v" + version
+	version := metadata.Tag
+
+	// fail runs the onFailure hooks before returning the original error
+	fail := func(phase hooks.Phase, err error) error {
+		if hookErr := hooks.ExecuteFailureHooks(config, metadata, phase, err); hookErr != nil {
+			logrus.Errorf("failed to execute %s hooks: %v", hooks.OnFailure, hookErr)
+		}
+		return err
 	}
 
 	if err := hooks.ExecuteHooks(config, hooks.Before, metadata); err != nil {
-		return fmt.Errorf("failed to execute hooks before release: %w", err)
+		return fail(hooks.PhaseBefore, fmt.Errorf("failed to execute hooks before release: %w", err))
+	}
+	if err := hooks.ExecuteCommandHooks(config, cfg.CommandProjectRelease, hooks.Before, metadata); err != nil {
+		return fail(hooks.PhaseBefore, fmt.Errorf("failed to execute hooks before release: %w", err))
 	}
 
 	if err := changelog.WriteChangelog(changelogFile, updatedChangelog); err != nil {
-		return fmt.Errorf("failed to update changelog: %w", err)
+		return fail(hooks.PhaseChangelog, fmt.Errorf("failed to update changelog: %w", err))
+	}
+	if err := hooks.ExecuteHooks(config, hooks.AfterChangelog, metadata); err != nil {
+		return fail(hooks.PhaseAfterChangelog, fmt.Errorf("failed to execute hooks after changelog update: %w", err))
 	}
 
-	hash, err := vcs.CommitChangelog(repoPath, changelogFile, version)
+	hash, err := vcs.CommitChangelog(repoPath, changelogFile, version, metadata.Fragments...)
 	if err != nil {
-		return fmt.Errorf("failed to commit changelog: %w", err)
+		return fail(hooks.PhaseCommit, fmt.Errorf("failed to commit changelog: %w", err))
+	}
+	// later hooks see the release commit, rather than the commit it was generated from
+	metadata.Sha = hash
+	if err := hooks.ExecuteHooks(config, hooks.AfterCommit, metadata); err != nil {
+		return fail(hooks.PhaseAfterCommit, fmt.Errorf("failed to execute hooks after commit: %w", err))
 	}
 
 	if err := vcs.TagRelease(repoPath, hash, version); err != nil {
-		return fmt.Errorf("failed to tag release commit: %s: %w", hash, err)
+		return fail(hooks.PhaseTag, fmt.Errorf("failed to tag release commit: %s: %w", hash, err))
+	}
+	if err := hooks.ExecuteHooks(config, hooks.AfterTag, metadata); err != nil {
+		return fail(hooks.PhaseAfterTag, fmt.Errorf("failed to execute hooks after tag: %w", err))
+	}
+
+	if push {
+		if err := vcs.PushRelease(repoPath, remote, version); err != nil {
+			return fail(hooks.PhasePush, fmt.Errorf("failed to push release: %w", err))
+		}
+		if err := hooks.ExecuteHooks(config, hooks.AfterPush, metadata); err != nil {
+			return fail(hooks.PhaseAfterPush, fmt.Errorf("failed to execute hooks after push: %w", err))
+		}
 	}
 
 	if err := hooks.ExecuteHooks(config, hooks.After, metadata); err != nil {
-		return fmt.Errorf("failed to execute hooks after release: %w", err)
+		return fail(hooks.PhaseAfter, fmt.Errorf("failed to execute hooks after release: %w", err))
+	}
+	if err := hooks.ExecuteCommandHooks(config, cfg.CommandProjectRelease, hooks.After, metadata); err != nil {
+		return fail(hooks.PhaseAfter, fmt.Errorf("failed to execute hooks after release: %w", err))
 	}
 
 	fmt.Printf("released version %s\n", version)
@@ -0,0 +1,158 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/release-tools/since/vcs"
+)
+
+func Test_release(t *testing.T) {
+	t.Run("passes the release commit to later hooks", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+		setGitUser(t, repoDir)
+		generatedFrom, err := vcs.GetHeadSha(repoDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		outDir := t.TempDir()
+		config := `before:
+  - script: echo "{{.Sha}}" > ` + filepath.Join(outDir, "before.txt") + `
+afterTag:
+  - script: echo "{{.Sha}}" > ` + filepath.Join(outDir, "after-tag.txt") + `
+`
+		if err := os.WriteFile(filepath.Join(repoDir, "since.yaml"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := release(vcs.CommitConfig{UniqueOnly: true}, changelogFile, vcs.TagOrderSemver, repoDir, false, ""); err != nil {
+			t.Fatalf("release() error = %v", err)
+		}
+		releaseCommit, err := vcs.GetHeadSha(repoDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for file, want := range map[string]string{"before.txt": generatedFrom, "after-tag.txt": releaseCommit} {
+			content, err := os.ReadFile(filepath.Join(outDir, file))
+			if err != nil {
+				t.Fatalf("hook did not run: %v", err)
+			}
+			if got := strings.TrimSpace(string(content)); got != want {
+				t.Errorf("hook writing %s got SHA %s, want %s", file, got, want)
+			}
+		}
+	})
+
+	t.Run("pushes the release and runs afterPush hooks", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+		setGitUser(t, repoDir)
+		remoteDir := t.TempDir()
+		remote, err := git.PlainInit(remoteDir, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addRemote(t, repoDir, remoteDir)
+
+		outDir := t.TempDir()
+		config := `afterPush:
+  - script: echo "{{.Tag}}" > ` + filepath.Join(outDir, "after-push.txt") + `
+`
+		if err := os.WriteFile(filepath.Join(repoDir, "since.yaml"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := release(vcs.CommitConfig{UniqueOnly: true}, changelogFile, vcs.TagOrderSemver, repoDir, true, "origin"); err != nil {
+			t.Fatalf("release() error = %v", err)
+		}
+		if _, err := remote.Tag("0.2.0"); err != nil {
+			t.Errorf("release tag was not pushed: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(outDir, "after-push.txt"))
+		if err != nil {
+			t.Fatalf("afterPush hook did not run: %v", err)
+		}
+		if got := strings.TrimSpace(string(content)); got != "0.2.0" {
+			t.Errorf("afterPush hook got tag %s, want 0.2.0", got)
+		}
+	})
+
+	t.Run("reports a failed push to onFailure hooks", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+		setGitUser(t, repoDir)
+
+		outDir := t.TempDir()
+		config := `afterPush:
+  - script: touch ` + filepath.Join(outDir, "after-push.txt") + `
+onFailure:
+  - script: echo "$SINCE_FAILED_PHASE" > ` + filepath.Join(outDir, "phase.txt") + `
+`
+		if err := os.WriteFile(filepath.Join(repoDir, "since.yaml"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := release(vcs.CommitConfig{UniqueOnly: true}, changelogFile, vcs.TagOrderSemver, repoDir, true, "missing"); err == nil {
+			t.Fatal("release() expected error pushing to a missing remote")
+		}
+		content, err := os.ReadFile(filepath.Join(outDir, "phase.txt"))
+		if err != nil {
+			t.Fatalf("onFailure hook did not run: %v", err)
+		}
+		if got := strings.TrimSpace(string(content)); got != "push" {
+			t.Errorf("onFailure hook got phase %s, want push", got)
+		}
+		if _, err := os.Stat(filepath.Join(outDir, "after-push.txt")); err == nil {
+			t.Error("afterPush hook ran after a failed push")
+		}
+	})
+}
+
+// addRemote adds a remote named origin to the repository.
+func addRemote(t *testing.T, repoDir string, url string) {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{url}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setGitUser configures the git user of the repository, so the release
+// can be committed without relying on the global git config.
+func setGitUser(t *testing.T, repoDir string) {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.User.Name = "user"
+	config.User.Email = "user@example.com"
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/stats"
+	"github.com/release-tools/since/vcs"
+	"github.com/spf13/cobra"
+)
+
+var statsArgs struct {
+	format string
+	from   string
+	to     string
+	unique bool
+}
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print statistics about the releases",
+	Long: `Reads the commit history for the current git repository, and
+prints statistics about its releases: the number of commits of each
+type and by each author, the average release size, the median time
+between releases, and the share of features and fixes.
+
+All releases are included, unless --from and/or --to tags are given.
+Both tags are included in the range. Unreleased commits are not counted.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitCfg := vcs.CommitConfig{
+			ExcludeTagCommits: projectArgs.excludeTagCommits,
+			UniqueOnly:        statsArgs.unique,
+			SkipCherryPicks:   projectArgs.skipCherryPicks,
+			UseCache:          projectArgs.cache,
+		}
+		output, err := projectStats(
+			commitCfg,
+			projectArgs.repoPath,
+			statsArgs.from,
+			statsArgs.to,
+			stats.Format(statsArgs.format),
+		)
+		if err != nil {
+			return err
+		}
+		fmt.Println(output)
+		return nil
+	},
+}
+
+func init() {
+	projectCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVarP(&statsArgs.format, "format", "f", string(stats.FormatTable), "Output format (table|json|csv)")
+	statsCmd.Flags().StringVar(&statsArgs.from, "from", "", "Include releases from this tag")
+	statsCmd.Flags().StringVar(&statsArgs.to, "to", "", "Include releases up to this tag")
+	statsCmd.Flags().BoolVar(&statsArgs.unique, "unique", true, "De-duplicate commit messages")
+}
+
+func projectStats(
+	commitCfg vcs.CommitConfig,
+	repoPath string,
+	fromTag string,
+	toTag string,
+	format stats.Format,
+) (string, error) {
+	config, err := cfg.LoadConfig(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	commits, _, err := vcs.FetchCommitsByTag(config, commitCfg, repoPath, toTag, "")
+	if err != nil {
+		return "", err
+	}
+
+	releases := *commits
+	if fromTag != "" {
+		// releases are ordered newest first, so stop after the from tag
+		found := false
+		for i, release := range releases {
+			if release.Name == fromTag {
+				releases = releases[:i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			// a tag whose commits are all filtered out has no release of
+			// its own, so only count the releases after it
+			inHistory, err := vcs.IsTagInHistory(repoPath, fromTag, toTag)
+			if err != nil {
+				return "", err
+			}
+			if !inHistory {
+				return "", fmt.Errorf("could not find tag %s in the history of %s", fromTag, describeTag(toTag))
+			}
+			after, _, err := vcs.FetchCommitsByTag(config, commitCfg, repoPath, toTag, fromTag)
+			if err != nil {
+				return "", err
+			}
+			releases = *after
+		}
+	}
+
+	mailmap, err := vcs.LoadMailmap(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return stats.Compute(releases, mailmap).Marshal(format)
+}
+
+// describeTag returns the tag name, or HEAD if the tag is empty.
+func describeTag(tag string) string {
+	if tag == "" {
+		return "HEAD"
+	}
+	return tag
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/release-tools/since/stats"
+	"github.com/release-tools/since/vcs"
+)
+
+func Test_projectStats(t *testing.T) {
+	repoDir, _ := createChangelogTestRepo(t)
+	commitCfg := vcs.CommitConfig{UniqueOnly: true}
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		format  stats.Format
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "all releases as CSV",
+			format: stats.FormatCSV,
+			want:   []string{"releases,,1", "commits,,1", "type,chore,1"},
+		},
+		{
+			name:   "range as JSON",
+			from:   "0.1.0",
+			to:     "0.1.0",
+			format: stats.FormatJSON,
+			want:   []string{`"releases": 1`, `"name": "chore"`},
+		},
+		{
+			name:    "unknown from tag",
+			from:    "9.9.9",
+			format:  stats.FormatTable,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported format",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := projectStats(commitCfg, repoDir, tt.from, tt.to, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("projectStats() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("projectStats() missing %q in:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func Test_projectStats_fromTagWithoutCommits(t *testing.T) {
+	repoDir, _ := createChangelogTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repoDir, "since.yaml"), []byte("ignore:\n  - '^docs:'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := func(name string, message string, offsetMillis int64) {
+		if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte(message), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Add("README.md"); err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: "user", Email: "user@example.com", When: time.UnixMilli(baseTimeMillis + offsetMillis)}
+		h, err := w.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.CreateTag(name, h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tag("0.1.1", "feat: add another feature", 20000)
+	// the only commit of 0.2.0 is ignored, so it has no release of its own
+	tag("0.2.0", "docs: update the readme", 30000)
+	tag("0.2.1", "fix: correct a typo", 40000)
+
+	got, err := projectStats(vcs.CommitConfig{UniqueOnly: true}, repoDir, "0.2.0", "", stats.FormatCSV)
+	if err != nil {
+		t.Fatalf("projectStats() error = %v", err)
+	}
+	for _, want := range []string{"releases,,1", "commits,,1", "type,fix,1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("projectStats() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "type,feat") {
+		t.Errorf("projectStats() included releases before the from tag:\n%s", got)
+	}
+}
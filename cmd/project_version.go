@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/changelog"
 	"github.com/release-tools/since/semver"
 	"github.com/release-tools/since/vcs"
 	"github.com/spf13/cobra"
@@ -26,8 +27,9 @@ import (
 )
 
 var versionArgs struct {
-	current bool
-	unique  bool
+	changelogFile string
+	current       bool
+	unique        bool
 }
 
 // versionCmd represents the version command
@@ -39,7 +41,9 @@ from the most recent tag. Returns the next semantic version
 based on the changes.
 
 Changes influence the version according to
-conventional commits: https://www.conventionalcommits.org/en/v1.0.0/`,
+conventional commits: https://www.conventionalcommits.org/en/v1.0.0/
+the bump of any changelog fragments, and any entries written by hand
+under Unreleased in the changelog.`,
 	Args:          cobra.NoArgs,
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -47,9 +51,12 @@ conventional commits: https://www.conventionalcommits.org/en/v1.0.0/`,
 		commitCfg := vcs.CommitConfig{
 			ExcludeTagCommits: projectArgs.excludeTagCommits,
 			UniqueOnly:        versionArgs.unique,
+			SkipCherryPicks:   projectArgs.skipCherryPicks,
+			UseCache:          projectArgs.cache,
 		}
 		version, err := printVersion(
 			commitCfg,
+			changelog.ResolveChangelogFile(projectArgs.repoPath, versionArgs.changelogFile),
 			projectArgs.repoPath,
 			projectArgs.tag,
 			vcs.TagOrderBy(projectArgs.orderBy),
@@ -69,12 +76,14 @@ conventional commits: https://www.conventionalcommits.org/en/v1.0.0/`,
 func init() {
 	projectCmd.AddCommand(versionCmd)
 
+	versionCmd.Flags().StringVar(&versionArgs.changelogFile, "changelog", "CHANGELOG.md", "Path to changelog file")
 	versionCmd.Flags().BoolVarP(&versionArgs.current, "current", "c", false, "Just print the current version")
 	versionCmd.Flags().BoolVar(&versionArgs.unique, "unique", true, "De-duplicate commit messages")
 }
 
 func printVersion(
 	commitCfg vcs.CommitConfig,
+	changelogFile string,
 	repoPath string,
 	tag string,
 	orderBy vcs.TagOrderBy,
@@ -107,9 +116,16 @@ func printVersion(
 		afterTag = tag
 	}
 
-	commits, err := vcs.FetchCommitMessages(config, commitCfg, repoPath, "", afterTag)
+	commits, _, err := vcs.FetchCommitsByTag(config, commitCfg, repoPath, "", afterTag)
 	if err != nil {
 		return "", err
 	}
-	return semver.GetNextVersion(currentVersion, vPrefix, commits), nil
+	bump, err := changelog.MinimumBump(config, repoPath, changelogFile)
+	if err != nil {
+		return "", err
+	}
+	for _, tagCommits := range *commits {
+		bump = semver.MaxComponent(bump, semver.BreakingChangeBump(tagCommits.CommitDetails()))
+	}
+	return semver.GetNextVersionWithBump(currentVersion, vPrefix, vcs.FlattenCommits(commits), bump), nil
 }
@@ -17,7 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 
 	"github.com/release-tools/since/semver"
 	"github.com/release-tools/since/vcs"
@@ -102,9 +108,9 @@ func Test_printVersion(t *testing.T) {
 	commitCfg := vcs.CommitConfig{UniqueOnly: true}
 
 	t.Run("returns the current version when current is set", func(t *testing.T) {
-		repoDir, _ := createChangelogTestRepo(t)
+		repoDir, changelogFile := createChangelogTestRepo(t)
 
-		got, err := printVersion(commitCfg, repoDir, "", vcs.TagOrderSemver, true)
+		got, err := printVersion(commitCfg, changelogFile, repoDir, "", vcs.TagOrderSemver, true)
 		if err != nil {
 			t.Fatalf("printVersion() error = %v", err)
 		}
@@ -114,9 +120,9 @@ func Test_printVersion(t *testing.T) {
 	})
 
 	t.Run("returns the next version based on unreleased commits", func(t *testing.T) {
-		repoDir, _ := createChangelogTestRepo(t)
+		repoDir, changelogFile := createChangelogTestRepo(t)
 
-		got, err := printVersion(commitCfg, repoDir, "", vcs.TagOrderSemver, false)
+		got, err := printVersion(commitCfg, changelogFile, repoDir, "", vcs.TagOrderSemver, false)
 		if err != nil {
 			t.Fatalf("printVersion() error = %v", err)
 		}
@@ -126,8 +132,56 @@ func Test_printVersion(t *testing.T) {
 		}
 	})
 
+	t.Run("bumps the major version for a breaking change footer", func(t *testing.T) {
+		repoDir, changelogFile := createChangelogTestRepo(t)
+		// the footer is the only sign of the breaking change
+		addTestCommit(t, repoDir, "feat: read YAML config\n\nBREAKING CHANGE: the config file is now YAML", 20000)
+
+		got, err := printVersion(commitCfg, changelogFile, repoDir, "", vcs.TagOrderSemver, false)
+		if err != nil {
+			t.Fatalf("printVersion() error = %v", err)
+		}
+		if got != "1.0.0" {
+			t.Errorf("printVersion() next = %q, want 1.0.0", got)
+		}
+	})
+
+	t.Run("bumps the version for entries written by hand under Unreleased", func(t *testing.T) {
+		// a repository without unreleased commits
+		repoDir := t.TempDir()
+		repo, err := git.PlainInit(repoDir, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := repo.Worktree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: "user", Email: "user@example.com", When: time.UnixMilli(baseTimeMillis)}
+		head, err := w.Commit("chore: initial commit", &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.CreateTag("0.1.0", head, nil); err != nil {
+			t.Fatal(err)
+		}
+		changelogFile := filepath.Join(repoDir, "CHANGELOG.md")
+		content := "# Change Log\n\n## [Unreleased]\n### Added\n- Support for importing projects\n\n## [0.1.0] - 2023-03-04\n### Changed\n- chore: initial commit\n"
+		if err := os.WriteFile(changelogFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := printVersion(commitCfg, changelogFile, repoDir, "", vcs.TagOrderSemver, false)
+		if err != nil {
+			t.Fatalf("printVersion() error = %v", err)
+		}
+		if got != "0.2.0" {
+			t.Errorf("printVersion() next = %q, want 0.2.0", got)
+		}
+	})
+
 	t.Run("returns error for a non-repository path", func(t *testing.T) {
-		if _, err := printVersion(commitCfg, t.TempDir(), "", vcs.TagOrderSemver, false); err == nil {
+		if _, err := printVersion(commitCfg, "CHANGELOG.md", t.TempDir(), "", vcs.TagOrderSemver, false); err == nil {
 			t.Error("printVersion() expected error for a non-repository path")
 		}
 	})
@@ -18,32 +18,153 @@ package convcommits
 
 import (
 	"golang.org/x/exp/maps"
+	"regexp"
 	"strings"
 )
 
+// BreakingChangeType is the type of commits that introduce a breaking change.
+const BreakingChangeType = "BREAKING CHANGE"
+
+// cherryPickPrefix starts the line added by 'git cherry-pick -x', which
+// git treats as part of the trailers.
+const cherryPickPrefix = "(cherry picked from commit "
+
+var (
+	breakingChangeFooterRegex = regexp.MustCompile(`^BREAKING[ -]CHANGE:\s*(.*)$`)
+	footerRegex               = regexp.MustCompile(`^[\w-]+(?::\s| #)`)
+	trailerRegex              = regexp.MustCompile(`^([\w-]+|BREAKING CHANGE)\s*:\s*(.*)$`)
+)
+
 func CategoriseByType(commits []string) map[string][]string {
 	categorised := make(map[string][]string)
 	for _, commit := range commits {
-		parts := strings.Split(commit, ":")
+		prefix := GetType(commit)
+		category := categorised[prefix]
+		category = append(category, commit)
+		categorised[prefix] = category
+	}
+	return categorised
+}
 
-		var prefix string
-		if len(parts) >= 2 {
-			prefix = strings.TrimSpace(parts[0])
-			if strings.HasSuffix(prefix, "!") {
-				prefix = "BREAKING CHANGE"
+// GetType returns the conventional commit type of the commit message,
+// such as 'feat', without any scope. Breaking changes, marked with '!',
+// have the type 'BREAKING CHANGE'. Messages without a type prefix
+// return an empty string.
+func GetType(commit string) string {
+	parts := strings.Split(commit, ":")
+	if len(parts) < 2 {
+		return ""
+	}
+	prefix := strings.TrimSpace(parts[0])
+	if strings.HasSuffix(prefix, "!") {
+		prefix = BreakingChangeType
+	}
+	if strings.Contains(prefix, "(") {
+		prefix = strings.Split(prefix, "(")[0]
+	}
+	return prefix
+}
+
+// GetScope returns the scope of the commit message, such as 'api' for
+// 'feat(api): add pagination', or an empty string if there is none.
+func GetScope(commit string) string {
+	parts := strings.Split(commit, ":")
+	if len(parts) < 2 {
+		return ""
+	}
+	prefix := parts[0]
+	start := strings.Index(prefix, "(")
+	end := strings.LastIndex(prefix, ")")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(prefix[start+1 : end])
+}
+
+// GetDescription returns the commit message without its type and scope
+// prefix, such as 'add pagination' for 'feat(api): add pagination'.
+func GetDescription(commit string) string {
+	if GetType(commit) == "" {
+		return strings.TrimSpace(commit)
+	}
+	return strings.TrimSpace(commit[strings.Index(commit, ":")+1:])
+}
+
+// GetBreakingChange returns the text of the 'BREAKING CHANGE:' footer in
+// the body of a commit message, or an empty string if there is none. A
+// footer that spans several lines is joined into a single line.
+func GetBreakingChange(body string) string {
+	var text []string
+	inFooter := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if m := breakingChangeFooterRegex.FindStringSubmatch(line); m != nil {
+			inFooter = true
+			line = m[1]
+		} else if inFooter && footerRegex.MatchString(line) {
+			break
+		}
+		if inFooter && line != "" {
+			text = append(text, line)
+		}
+	}
+	return strings.Join(text, " ")
+}
+
+// GetTrailerValues returns the values of the trailers in the body of a
+// commit message with the given key, such as 'Co-authored-by'. Keys are
+// compared case-insensitively. As with git interpret-trailers, trailers
+// are only read from the last paragraph of the body, and only if every
+// line of it is a trailer, the indented continuation of one, or a
+// '(cherry picked from commit ...)' line.
+func GetTrailerValues(body string, key string) []string {
+	var values []string
+	matched := false
+	for i, line := range lastParagraph(body) {
+		if line != strings.TrimLeft(line, " \t") {
+			if i == 0 {
+				return nil
 			}
-			if strings.Contains(prefix, "(") {
-				prefix = strings.Split(prefix, "(")[0]
+			if matched {
+				values[len(values)-1] = strings.TrimSpace(values[len(values)-1] + " " + strings.TrimSpace(line))
 			}
-		} else {
-			prefix = ""
+			continue
+		}
+		if strings.HasPrefix(line, cherryPickPrefix) {
+			matched = false
+			continue
+		}
+		m := trailerRegex.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		matched = strings.EqualFold(m[1], key)
+		if matched {
+			values = append(values, strings.TrimSpace(m[2]))
 		}
+	}
+	var nonEmpty []string
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return nonEmpty
+}
 
-		category := categorised[prefix]
-		category = append(category, commit)
-		categorised[prefix] = category
+// lastParagraph returns the lines of the last paragraph of the body,
+// ignoring any trailing blank lines.
+func lastParagraph(body string) []string {
+	lines := strings.Split(strings.TrimRight(body, " \t\r\n"), "\n")
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
 	}
-	return categorised
+	paragraph := lines[start:]
+	for i, line := range paragraph {
+		paragraph[i] = strings.TrimRight(line, " \t\r")
+	}
+	return paragraph
 }
 
 func DetermineTypes(commits []string) []string {
@@ -108,3 +108,122 @@ func TestDetermineTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestGetScopeAndDescription(t *testing.T) {
+	tests := []struct {
+		commit          string
+		wantType        string
+		wantScope       string
+		wantDescription string
+	}{
+		{commit: "feat(api): add pagination", wantType: "feat", wantScope: "api", wantDescription: "add pagination"},
+		{commit: "fix(ui)!: drop old layout", wantType: "BREAKING CHANGE", wantScope: "ui", wantDescription: "drop old layout"},
+		{commit: "chore: tidy up", wantType: "chore", wantDescription: "tidy up"},
+		{commit: "some random commit message", wantDescription: "some random commit message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.commit, func(t *testing.T) {
+			if got := GetType(tt.commit); got != tt.wantType {
+				t.Errorf("GetType() = %v, want %v", got, tt.wantType)
+			}
+			if got := GetScope(tt.commit); got != tt.wantScope {
+				t.Errorf("GetScope() = %v, want %v", got, tt.wantScope)
+			}
+			if got := GetDescription(tt.commit); got != tt.wantDescription {
+				t.Errorf("GetDescription() = %v, want %v", got, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestGetBreakingChange(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "no body", body: "", want: ""},
+		{name: "no footer", body: "Explains the change.", want: ""},
+		{
+			name: "footer",
+			body: "Explains the change.\n\nBREAKING CHANGE: the config file is now YAML",
+			want: "the config file is now YAML",
+		},
+		{
+			name: "hyphenated footer",
+			body: "BREAKING-CHANGE: drop support for Go 1.19",
+			want: "drop support for Go 1.19",
+		},
+		{
+			name: "multi-line footer followed by another footer",
+			body: "BREAKING CHANGE: rename the 'since.yml' file\nto 'since.yaml'\nRefs: #123",
+			want: "rename the 'since.yml' file to 'since.yaml'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetBreakingChange(tt.body); got != tt.want {
+				t.Errorf("GetBreakingChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTrailerValues(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		key  string
+		want []string
+	}{
+		{
+			name: "trailers in the last paragraph",
+			body: "Adds pagination.\n\nCo-authored-by: Jane Doe <jane@example.com>\nco-authored-by: Joe Bloggs <joe@example.com>\nSigned-off-by: Jane Doe <jane@example.com>",
+			key:  "Co-authored-by",
+			want: []string{"Jane Doe <jane@example.com>", "Joe Bloggs <joe@example.com>"},
+		},
+		{
+			name: "missing key",
+			body: "Adds pagination.\n\nSigned-off-by: Jane Doe <jane@example.com>",
+			key:  "Reviewed-by",
+			want: nil,
+		},
+		{
+			name: "key in an earlier paragraph",
+			body: "Note: the API is unchanged.\n\nSigned-off-by: Jane Doe <jane@example.com>",
+			key:  "Note",
+			want: nil,
+		},
+		{
+			name: "last paragraph is not all trailers",
+			body: "Adds pagination.\nChangelog: skip",
+			key:  "Changelog",
+			want: nil,
+		},
+		{
+			name: "continuation line",
+			body: "Adds pagination.\n\nRelease-Note: pages are now\n  50 items long\nChangelog: skip\n",
+			key:  "Release-Note",
+			want: []string{"pages are now 50 items long"},
+		},
+		{
+			name: "alongside a cherry-pick line",
+			body: "Changelog: skip\n(cherry picked from commit 0123456789abcdef0123456789abcdef01234567)",
+			key:  "Changelog",
+			want: []string{"skip"},
+		},
+		{
+			name: "alongside a breaking change footer",
+			body: "BREAKING CHANGE: pages are smaller\nChangelog: skip",
+			key:  "Changelog",
+			want: []string{"skip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetTrailerValues(tt.body, tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTrailerValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
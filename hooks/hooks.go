@@ -17,22 +17,62 @@ limitations under the License.
 package hooks
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/release-tools/since/cfg"
 	"github.com/release-tools/since/vcs"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 )
 
 type HookType string
 
 const (
-	Before HookType = "before"
-	After  HookType = "after"
+	Before         HookType = "before"
+	AfterChangelog HookType = "afterChangelog"
+	AfterCommit    HookType = "afterCommit"
+	AfterTag       HookType = "afterTag"
+	AfterPush      HookType = "afterPush"
+	After          HookType = "after"
+	OnFailure      HookType = "onFailure"
 )
 
+// Phase identifies the step of a release that was running when it failed.
+// The phase of a failing hook is named after its HookType.
+type Phase string
+
+const (
+	PhaseBefore         Phase = "before"
+	PhaseChangelog      Phase = "changelog"
+	PhaseAfterChangelog Phase = "afterChangelog"
+	PhaseCommit         Phase = "commit"
+	PhaseAfterCommit    Phase = "afterCommit"
+	PhaseTag            Phase = "tag"
+	PhaseAfterTag       Phase = "afterTag"
+	PhasePush           Phase = "push"
+	PhaseAfterPush      Phase = "afterPush"
+	PhaseAfter          Phase = "after"
+)
+
+// waitDelay bounds how long to wait for a hook's I/O to close after
+// it has been killed following a timeout.
+const waitDelay = 5 * time.Second
+
+// shellInterpreters maps the supported hook shells to their interpreters.
+var shellInterpreters = map[string]string{
+	"bash":   "bash",
+	"sh":     "sh",
+	"python": "python3",
+}
+
+// scriptInterpreter is used for script hooks that do not specify a shell.
 var scriptInterpreter string
 
 func init() {
@@ -44,69 +84,200 @@ func init() {
 
 // ExecuteHooks executes all hooks of the given type
 func ExecuteHooks(config cfg.SinceConfig, hookType HookType, metadata vcs.ReleaseMetadata) error {
+	hooks, err := getHooks(config, hookType)
+	if err != nil {
+		return err
+	}
+	logrus.Tracef("%d %v hooks found", len(hooks), hookType)
+	return executeAll(hooks, metadata)
+}
+
+// ExecuteCommandHooks executes the hooks of the given type that are attached
+// to a specific command, such as cfg.CommandChangelogUpdate. Only the
+// Before and After types are supported.
+func ExecuteCommandHooks(config cfg.SinceConfig, command string, hookType HookType, metadata vcs.ReleaseMetadata) error {
+	commandHooks := config.Commands[command]
+
 	var hooks []cfg.Hook
 	switch hookType {
 	case Before:
-		hooks = config.Before
+		hooks = commandHooks.Before
 	case After:
-		hooks = config.After
+		hooks = commandHooks.After
 	default:
-		return fmt.Errorf("invalid hook type: %s", hookType)
+		return fmt.Errorf("invalid hook type for command '%s': %s", command, hookType)
 	}
+	logrus.Tracef("%d %v hooks found for command '%s'", len(hooks), hookType, command)
+	return executeAll(hooks, metadata)
+}
 
-	logrus.Tracef("%d %v hooks found", len(hooks), hookType)
+// executeAll executes the given hooks in order, stopping at the first
+// failure unless the hook is configured to continue on error.
+func executeAll(hooks []cfg.Hook, metadata vcs.ReleaseMetadata) error {
+	data := templateData{ReleaseMetadata: metadata}
 	for _, hook := range hooks {
-		err := executeHook(hook, metadata)
-		if err != nil {
+		err := executeHook(hook, data, nil)
+		if err != nil && hook.ContinueOnError {
+			logrus.Warnf("ignoring failure of hook '%s %s': %v", hook.Command, strings.Join(hook.Args, " "), err)
+		} else if err != nil {
 			return fmt.Errorf("error executing hook '%s %s': %v", hook.Command, strings.Join(hook.Args, " "), err)
 		}
 	}
-
 	return nil
 }
 
-// executeHook executes a hook command with the given arguments
-func executeHook(hook cfg.Hook, metadata vcs.ReleaseMetadata) error {
-	var command string
-	var args []string
+// ExecuteFailureHooks executes all onFailure hooks, passing the phase
+// that failed and its error in the SINCE_FAILED_PHASE and SINCE_ERROR
+// environment variables. Every hook is attempted, even if an earlier
+// one fails, so that cleanup steps are not skipped.
+func ExecuteFailureHooks(config cfg.SinceConfig, metadata vcs.ReleaseMetadata, phase Phase, cause error) error {
+	logrus.Tracef("%d %v hooks found", len(config.OnFailure), OnFailure)
+	env := []string{
+		"SINCE_FAILED_PHASE=" + string(phase),
+		"SINCE_ERROR=" + cause.Error(),
+	}
 
-	if hook.Script != "" {
-		if hook.Command != "" {
-			return fmt.Errorf("hook cannot specify both a command and a script")
-		}
+	data := templateData{
+		ReleaseMetadata: metadata,
+		Phase:           string(phase),
+		Error:           cause.Error(),
+	}
 
-		script, err := os.CreateTemp(os.TempDir(), "since-hook*.sh")
+	var errs []error
+	for _, hook := range config.OnFailure {
+		err := executeHook(hook, data, env)
 		if err != nil {
-			return fmt.Errorf("error creating temporary script file: %v", err)
+			errs = append(errs, fmt.Errorf("error executing hook '%s %s': %v", hook.Command, strings.Join(hook.Args, " "), err))
 		}
-		defer os.Remove(script.Name())
-		err = os.WriteFile(script.Name(), []byte(hook.Script), 0755)
-		if err != nil {
+	}
+	return errors.Join(errs...)
+}
+
+// getHooks returns the hooks configured for the given type.
+func getHooks(config cfg.SinceConfig, hookType HookType) ([]cfg.Hook, error) {
+	switch hookType {
+	case Before:
+		return config.Before, nil
+	case AfterChangelog:
+		return config.AfterChangelog, nil
+	case AfterCommit:
+		return config.AfterCommit, nil
+	case AfterTag:
+		return config.AfterTag, nil
+	case AfterPush:
+		return config.AfterPush, nil
+	case After:
+		return config.After, nil
+	case OnFailure:
+		return config.OnFailure, nil
+	default:
+		return nil, fmt.Errorf("invalid hook type: %s", hookType)
+	}
+}
+
+// executeHook renders the hook's templates, then executes it, retrying
+// it if it fails and retries are configured.
+func executeHook(hook cfg.Hook, data templateData, env []string) error {
+	hook, err := renderHook(hook, data)
+	if err != nil {
+		return err
+	}
+	command, args, cleanup, err := resolveCommand(hook)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	attempts := hook.Retries + 1
+	for attempt := 1; ; attempt++ {
+		err = execCommand(hook, command, args, data.ReleaseMetadata, env)
+		if err == nil || attempt >= attempts {
 			return err
 		}
-		command = scriptInterpreter
-		args = []string{script.Name()}
-	} else {
-		command = hook.Command
-		args = hook.Args
+		logrus.Warnf("hook '%s %s' failed (attempt %d of %d): %v", command, strings.Join(args, " "), attempt, attempts, err)
+	}
+}
+
+// resolveCommand returns the command and arguments to run for a hook.
+// For script hooks, the script is written to a temporary file, which is
+// removed by the returned cleanup function.
+func resolveCommand(hook cfg.Hook) (command string, args []string, cleanup func(), err error) {
+	cleanup = func() {}
+	if hook.Script == "" {
+		if hook.Shell != "" {
+			return "", nil, cleanup, fmt.Errorf("hook shell can only be set for script hooks")
+		}
+		return hook.Command, hook.Args, cleanup, nil
+	}
+
+	if hook.Command != "" {
+		return "", nil, cleanup, fmt.Errorf("hook cannot specify both a command and a script")
+	}
+	interpreter := scriptInterpreter
+	if hook.Shell != "" {
+		var ok bool
+		interpreter, ok = shellInterpreters[hook.Shell]
+		if !ok {
+			return "", nil, cleanup, fmt.Errorf("unsupported hook shell: %s", hook.Shell)
+		}
 	}
-	return execCommand(command, args, metadata)
+
+	script, err := os.CreateTemp(os.TempDir(), "since-hook*")
+	if err != nil {
+		return "", nil, cleanup, fmt.Errorf("error creating temporary script file: %v", err)
+	}
+	cleanup = func() { _ = os.Remove(script.Name()) }
+	if _, err := script.WriteString(hook.Script); err != nil {
+		_ = script.Close()
+		cleanup()
+		return "", nil, func() {}, fmt.Errorf("error writing temporary script file: %v", err)
+	}
+	_ = script.Close()
+	return interpreter, []string{script.Name()}, cleanup, nil
 }
 
-func execCommand(command string, args []string, metadata vcs.ReleaseMetadata) error {
+func execCommand(hook cfg.Hook, command string, args []string, metadata vcs.ReleaseMetadata, env []string) error {
 	logrus.Debugf("executing hook '%s %s'", command, strings.Join(args, " "))
 
-	cmd := exec.Command(command, args...)
-	cmd.Dir = metadata.RepoPath
+	ctx := context.Background()
+	if hook.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	if hook.Timeout > 0 {
+		// run the hook in its own process group, so that any children
+		// it spawns are also killed when it times out
+		setProcessGroup(cmd)
+		cmd.Cancel = func() error {
+			return killProcessGroup(cmd)
+		}
+		cmd.WaitDelay = waitDelay
+	}
+	cmd.Dir = resolveWorkingDir(hook.WorkingDir, metadata.RepoPath)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Env = append(os.Environ(), []string{
 		"SINCE_NEW_VERSION=" + metadata.NewVersion,
 		"SINCE_OLD_VERSION=" + metadata.OldVersion,
 		"SINCE_SHA=" + metadata.Sha,
+		"SINCE_REPO_PATH=" + metadata.RepoPath,
+		"SINCE_TAG=" + metadata.Tag,
 	}...)
+	cmd.Env = append(cmd.Env, env...)
+
+	keys := maps.Keys(hook.Env)
+	sort.Strings(keys)
+	for _, key := range keys {
+		cmd.Env = append(cmd.Env, key+"="+hook.Env[key])
+	}
 
 	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("hook '%s %s' timed out after %v", command, strings.Join(args, " "), hook.Timeout)
+	}
 	if err != nil {
 		return fmt.Errorf("error executing hook '%s %s': %v", command, strings.Join(args, " "), err)
 	}
@@ -117,3 +288,15 @@ func execCommand(command string, args []string, metadata vcs.ReleaseMetadata) er
 	}
 	return nil
 }
+
+// resolveWorkingDir returns the directory to run a hook in. Relative
+// paths are resolved against the repository path.
+func resolveWorkingDir(workingDir string, repoPath string) string {
+	if workingDir == "" {
+		return repoPath
+	}
+	if filepath.IsAbs(workingDir) {
+		return workingDir
+	}
+	return filepath.Join(repoPath, workingDir)
+}
@@ -17,9 +17,14 @@ limitations under the License.
 package hooks
 
 import (
+	"errors"
 	"github.com/release-tools/since/cfg"
 	"github.com/release-tools/since/vcs"
+	"os"
+	"path"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestExecuteHooks(t *testing.T) {
@@ -85,6 +90,101 @@ func TestExecuteHooks(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "lifecycle hook",
+			args: args{
+				config: cfg.SinceConfig{
+					AfterTag: []cfg.Hook{
+						{
+							Command: "false",
+						},
+					},
+				},
+				metadata: vcs.ReleaseMetadata{},
+				hookType: AfterTag,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid hook type",
+			args: args{
+				config:   cfg.SinceConfig{},
+				metadata: vcs.ReleaseMetadata{},
+				hookType: "unknown",
+			},
+			wantErr: true,
+		},
+		{
+			name: "continue on error",
+			args: args{
+				config: cfg.SinceConfig{
+					Before: []cfg.Hook{
+						{
+							Command:         "false",
+							ContinueOnError: true,
+						},
+						{
+							Command: "true",
+						},
+					},
+				},
+				metadata: vcs.ReleaseMetadata{},
+				hookType: Before,
+			},
+			wantErr: false,
+		},
+		{
+			name: "script with shell",
+			args: args{
+				config: cfg.SinceConfig{
+					Before: []cfg.Hook{
+						{
+							Script: `[ -n "$0" ]`,
+							Shell:  "sh",
+						},
+					},
+				},
+				metadata: vcs.ReleaseMetadata{},
+				hookType: Before,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported shell",
+			args: args{
+				config: cfg.SinceConfig{
+					Before: []cfg.Hook{
+						{
+							Script: `echo "hello world"`,
+							Shell:  "cobol",
+						},
+					},
+				},
+				metadata: vcs.ReleaseMetadata{},
+				hookType: Before,
+			},
+			wantErr: true,
+		},
+		{
+			name: "hook env and working dir",
+			args: args{
+				config: cfg.SinceConfig{
+					Before: []cfg.Hook{
+						{
+							Command:    "bash",
+							Args:       []string{"-c", `[ "$GREETING" == "hello" ] && [ "$(basename "$PWD")" == "hooks" ]`},
+							Env:        map[string]string{"GREETING": "hello"},
+							WorkingDir: "hooks",
+						},
+					},
+				},
+				metadata: vcs.ReleaseMetadata{
+					RepoPath: "..",
+				},
+				hookType: Before,
+			},
+			wantErr: false,
+		},
 		{
 			name: "env substitution",
 			args: args{
@@ -112,3 +212,111 @@ func TestExecuteHooks(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteFailureHooks(t *testing.T) {
+	marker := path.Join(t.TempDir(), "marker")
+	config := cfg.SinceConfig{
+		OnFailure: []cfg.Hook{
+			{
+				Command: "false",
+			},
+			{
+				Command: "bash",
+				Args:    []string{"-c", `[ "$SINCE_FAILED_PHASE" == "tag" ] && [ "$SINCE_ERROR" == "tag exists" ] && touch ` + marker},
+			},
+		},
+	}
+
+	err := ExecuteFailureHooks(config, vcs.ReleaseMetadata{}, PhaseTag, errors.New("tag exists"))
+	if err == nil {
+		t.Fatal("ExecuteFailureHooks() expected error from failing hook")
+	}
+
+	// the first hook fails, but the second should still run
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("ExecuteFailureHooks() did not run second hook with failure details: %v", err)
+	}
+}
+
+func TestExecuteHooks_retries(t *testing.T) {
+	counter := path.Join(t.TempDir(), "counter")
+	config := cfg.SinceConfig{
+		Before: []cfg.Hook{
+			{
+				// fails until the third attempt
+				Script:  `echo x >> ` + counter + ` && [ "$(wc -l < ` + counter + `)" -ge 3 ]`,
+				Retries: 2,
+			},
+		},
+	}
+	if err := ExecuteHooks(config, Before, vcs.ReleaseMetadata{}); err != nil {
+		t.Fatalf("ExecuteHooks() error = %v", err)
+	}
+
+	// a fresh counter with too few retries should fail
+	_ = os.Remove(counter)
+	config.Before[0].Retries = 1
+	if err := ExecuteHooks(config, Before, vcs.ReleaseMetadata{}); err == nil {
+		t.Error("ExecuteHooks() expected error when retries are exhausted")
+	}
+}
+
+func TestExecuteHooks_timeout(t *testing.T) {
+	config := cfg.SinceConfig{
+		Before: []cfg.Hook{
+			{
+				// the script waits for its child, so it only returns when it
+				// is killed
+				Script:  `sleep 30 & wait`,
+				Timeout: 200 * time.Millisecond,
+			},
+		},
+	}
+
+	start := time.Now()
+	err := ExecuteHooks(config, Before, vcs.ReleaseMetadata{})
+	if err == nil {
+		t.Fatal("ExecuteHooks() expected timeout error")
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("ExecuteHooks() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > waitDelay {
+		t.Errorf("ExecuteHooks() took %v, want hook to be killed promptly", elapsed)
+	}
+}
+
+func TestExecuteCommandHooks(t *testing.T) {
+	config := cfg.SinceConfig{
+		Before: []cfg.Hook{
+			{
+				Command: "false",
+			},
+		},
+		Commands: map[string]cfg.CommandHooks{
+			cfg.CommandChangelogUpdate: {
+				After: []cfg.Hook{
+					{
+						Command: "bash",
+						Args:    []string{"-c", `[ "{{.NewVersion}}" == "1.0.0" ]`},
+					},
+				},
+			},
+		},
+	}
+	metadata := vcs.ReleaseMetadata{NewVersion: "1.0.0"}
+
+	// top-level hooks should not run for a command
+	if err := ExecuteCommandHooks(config, cfg.CommandChangelogUpdate, Before, metadata); err != nil {
+		t.Errorf("ExecuteCommandHooks() before error = %v", err)
+	}
+	if err := ExecuteCommandHooks(config, cfg.CommandChangelogUpdate, After, metadata); err != nil {
+		t.Errorf("ExecuteCommandHooks() after error = %v", err)
+	}
+	if err := ExecuteCommandHooks(config, cfg.CommandChangelogGenerate, After, metadata); err != nil {
+		t.Errorf("ExecuteCommandHooks() for command without hooks error = %v", err)
+	}
+	if err := ExecuteCommandHooks(config, cfg.CommandChangelogUpdate, AfterTag, metadata); err == nil {
+		t.Error("ExecuteCommandHooks() expected error for unsupported hook type")
+	}
+}
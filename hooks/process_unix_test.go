@@ -0,0 +1,83 @@
+//go:build !windows
+
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+)
+
+func TestExecuteHooks_timeoutKillsChildren(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	config := cfg.SinceConfig{
+		Before: []cfg.Hook{
+			{
+				Script:  `sleep 30 & echo $! > "$PID_FILE"; wait`,
+				Timeout: 200 * time.Millisecond,
+				Env:     map[string]string{"PID_FILE": pidFile},
+			},
+		},
+	}
+
+	if err := ExecuteHooks(config, Before, vcs.ReleaseMetadata{}); err == nil {
+		t.Fatal("ExecuteHooks() expected timeout error")
+	}
+
+	content, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("failed to read child PID: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		t.Fatalf("invalid child PID %q: %v", content, err)
+	}
+	defer func() { _ = syscall.Kill(pid, syscall.SIGKILL) }()
+
+	// the child is reaped asynchronously once its parent is killed
+	deadline := time.Now().Add(2 * time.Second)
+	for isRunning(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("child process %d is still running after the hook timed out", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// isRunning returns true if the process exists and has not exited.
+// A zombie, which has exited but not yet been reaped, is not running.
+func isRunning(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return !os.IsNotExist(err)
+	}
+	// the state follows the command name, which is in parentheses
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateData is the data available to hook templates. The release
+// metadata fields, such as {{.NewVersion}}, {{.Tag}} and {{.Notes}}, are
+// promoted from the embedded ReleaseMetadata. Phase and Error are only
+// set for onFailure hooks.
+type templateData struct {
+	vcs.ReleaseMetadata
+	Phase string
+	Error string
+}
+
+// templateFuncs are the helper functions available to hook templates.
+var templateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+	"join":       func(sep string, s []string) string { return strings.Join(s, sep) },
+	"env":        os.Getenv,
+	"quote":      shellQuote,
+	"default": func(def string, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+}
+
+// renderHook returns a copy of the hook with its command, arguments and
+// script rendered as Go templates against the given data, unless the hook
+// opts out of templating.
+func renderHook(hook cfg.Hook, data templateData) (cfg.Hook, error) {
+	if !hook.IsTemplate() {
+		return hook, nil
+	}
+	var err error
+	if hook.Command, err = renderTemplate("command", hook.Command, data); err != nil {
+		return cfg.Hook{}, err
+	}
+	if hook.Script, err = renderTemplate("script", hook.Script, data); err != nil {
+		return cfg.Hook{}, err
+	}
+	if hook.Args != nil {
+		args := make([]string, len(hook.Args))
+		for i, arg := range hook.Args {
+			if args[i], err = renderTemplate("args", arg, data); err != nil {
+				return cfg.Hook{}, err
+			}
+		}
+		hook.Args = args
+	}
+	return hook, nil
+}
+
+// renderTemplate renders a single template string. As the data is a
+// struct, references to unknown fields fail when the template is rendered.
+func renderTemplate(name string, text string, data templateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse hook %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render hook %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// shellQuote quotes a string for safe use as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/vcs"
+	"reflect"
+	"testing"
+)
+
+func Test_renderTemplate(t *testing.T) {
+	data := templateData{
+		ReleaseMetadata: vcs.ReleaseMetadata{
+			NewVersion: "1.2.0",
+			OldVersion: "1.1.0",
+			Tag:        "v1.2.0",
+			Notes:      "## [1.2.0]\n- feat: it's new",
+		},
+		Phase: "tag",
+	}
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no template",
+			text: "echo hello",
+			want: "echo hello",
+		},
+		{
+			name: "metadata fields",
+			text: "release {{.Tag}} ({{.OldVersion}} -> {{.NewVersion}})",
+			want: "release v1.2.0 (1.1.0 -> 1.2.0)",
+		},
+		{
+			name: "helper functions",
+			text: "{{.Tag | trimPrefix \"v\" | upper}} {{.Error | default \"none\"}} {{.Phase}}",
+			want: "1.2.0 none tag",
+		},
+		{
+			name: "quoted notes",
+			text: "echo {{quote .Notes}}",
+			want: "echo '## [1.2.0]\n- feat: it'\\''s new'",
+		},
+		{
+			name:    "unknown field",
+			text:    "{{.Version}}",
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			text:    "{{.Tag",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate("test", tt.text, data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("renderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("renderTemplate() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_renderHook(t *testing.T) {
+	hook := cfg.Hook{
+		Command: "gh",
+		Args:    []string{"release", "create", "{{.Tag}}", "--notes", "{{.Notes}}"},
+	}
+	data := templateData{ReleaseMetadata: vcs.ReleaseMetadata{Tag: "v1.0.0", Notes: "notes"}}
+
+	got, err := renderHook(hook, data)
+	if err != nil {
+		t.Fatalf("renderHook() error = %v", err)
+	}
+	want := []string{"release", "create", "v1.0.0", "--notes", "notes"}
+	if !reflect.DeepEqual(got.Args, want) {
+		t.Errorf("renderHook() args = %v, want %v", got.Args, want)
+	}
+
+	// the original hook should not be modified
+	if hook.Args[2] != "{{.Tag}}" {
+		t.Errorf("renderHook() modified original args: %v", hook.Args)
+	}
+}
+
+func Test_renderHook_literalBraces(t *testing.T) {
+	data := templateData{ReleaseMetadata: vcs.ReleaseMetadata{Tag: "v1.0.0"}}
+	template := false
+	tests := []struct {
+		name string
+		hook cfg.Hook
+	}{
+		{
+			name: "escaped braces",
+			hook: cfg.Hook{Command: "docker", Args: []string{"inspect", "--format", `{{"{{"}}.Id}}`, "app"}},
+		},
+		{
+			name: "templating disabled",
+			hook: cfg.Hook{Command: "docker", Args: []string{"inspect", "--format", "{{.Id}}", "app"}, Template: &template},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderHook(tt.hook, data)
+			if err != nil {
+				t.Fatalf("renderHook() error = %v", err)
+			}
+			want := []string{"inspect", "--format", "{{.Id}}", "app"}
+			if !reflect.DeepEqual(got.Args, want) {
+				t.Errorf("renderHook() args = %v, want %v", got.Args, want)
+			}
+		})
+	}
+}
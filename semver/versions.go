@@ -17,6 +17,7 @@ limitations under the License.
 package semver
 
 import (
+	"fmt"
 	"github.com/release-tools/since/convcommits"
 	"github.com/release-tools/since/stringutil"
 	"github.com/release-tools/since/vcs"
@@ -48,12 +49,46 @@ func GetCurrentVersion(repoPath string, orderBy vcs.TagOrderBy) (version string,
 	return version, vPrefix, nil
 }
 
+// componentRanks orders the components by the size of the change.
+var componentRanks = map[Component]int{
+	ComponentNone:  0,
+	ComponentPatch: 1,
+	ComponentMinor: 2,
+	ComponentMajor: 3,
+}
+
+// ParseComponent returns the component with the given name.
+func ParseComponent(name string) (Component, error) {
+	component := Component(strings.ToLower(name))
+	if _, found := componentRanks[component]; !found {
+		return "", fmt.Errorf("unsupported version bump: %s, must be one of: %s, %s, %s, %s", name, ComponentMajor, ComponentMinor, ComponentPatch, ComponentNone)
+	}
+	return component, nil
+}
+
+// MaxComponent returns the component with the largest change.
+func MaxComponent(a Component, b Component) Component {
+	if componentRanks[b] > componentRanks[a] {
+		return b
+	}
+	return a
+}
+
 // GetNextVersion gets the next version based on the current version and the commit messages.
 func GetNextVersion(currentVersion string, vPrefix bool, commits []string) string {
-	types := convcommits.DetermineTypes(commits)
-	logrus.Debugf("commit types: %v", types)
+	return GetNextVersionWithBump(currentVersion, vPrefix, commits, ComponentNone)
+}
 
-	changeType := DetermineChangeType(types)
+// GetNextVersionWithBump gets the next version based on the current version
+// and the commit messages, bumping at least the given component, such as
+// the bump requested by changelog fragments.
+func GetNextVersionWithBump(currentVersion string, vPrefix bool, commits []string, minimum Component) string {
+	changeType := minimum
+	if len(commits) > 0 {
+		types := convcommits.DetermineTypes(commits)
+		logrus.Debugf("commit types: %v", types)
+		changeType = MaxComponent(DetermineChangeType(types), minimum)
+	}
 	if changeType == ComponentNone {
 		logrus.Warnf("no changes detected")
 		return ""
@@ -66,6 +101,19 @@ func GetNextVersion(currentVersion string, vPrefix bool, commits []string) strin
 	return nextVersion
 }
 
+// BreakingChangeBump returns a major bump if any of the commits has a
+// 'BREAKING CHANGE:' footer, which makes it a breaking change even if its
+// subject is not marked with '!', or no bump otherwise.
+func BreakingChangeBump(details []vcs.CommitDetail) Component {
+	for _, detail := range details {
+		if convcommits.GetBreakingChange(detail.Body) != "" {
+			logrus.Debugf("commit '%s' has a breaking change footer", detail.Message)
+			return ComponentMajor
+		}
+	}
+	return ComponentNone
+}
+
 // bumpVersion bumps the version based on the component.
 func bumpVersion(version string, component Component) string {
 	logrus.Tracef("bumping %v component", component)
@@ -247,3 +247,96 @@ func TestBumpVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNextVersionWithBump(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []string
+		minimum Component
+		want    string
+	}{
+		{
+			name:    "commits require a larger bump",
+			commits: []string{"feat: new feature"},
+			minimum: ComponentPatch,
+			want:    "1.3.0",
+		},
+		{
+			name:    "minimum requires a larger bump",
+			commits: []string{"fix: a bug"},
+			minimum: ComponentMajor,
+			want:    "2.0.0",
+		},
+		{
+			name:    "no commits",
+			minimum: ComponentMinor,
+			want:    "1.3.0",
+		},
+		{
+			name:    "no commits and no minimum",
+			minimum: ComponentNone,
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetNextVersionWithBump("1.2.3", false, tt.commits, tt.minimum); got != tt.want {
+				t.Errorf("GetNextVersionWithBump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakingChangeBump(t *testing.T) {
+	tests := []struct {
+		name    string
+		details []vcs.CommitDetail
+		want    Component
+	}{
+		{
+			name: "breaking change footer",
+			details: []vcs.CommitDetail{
+				{Message: "fix: a bug"},
+				{Message: "feat: read YAML config", Body: "BREAKING CHANGE: the config file is now YAML"},
+			},
+			want: ComponentMajor,
+		},
+		{
+			name:    "no footer",
+			details: []vcs.CommitDetail{{Message: "feat!: read YAML config", Body: "The config file is now YAML."}},
+			want:    ComponentNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BreakingChangeBump(tt.details); got != tt.want {
+				t.Errorf("BreakingChangeBump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseComponent(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Component
+		wantErr bool
+	}{
+		{name: "major", want: ComponentMajor},
+		{name: "Minor", want: ComponentMinor},
+		{name: "patch", want: ComponentPatch},
+		{name: "none", want: ComponentNone},
+		{name: "huge", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseComponent(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComponent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseComponent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
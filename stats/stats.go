@@ -0,0 +1,236 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/release-tools/since/convcommits"
+	"github.com/release-tools/since/vcs"
+	"golang.org/x/exp/slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
+// otherType is the type of commits without a conventional commit type.
+const otherType = "other"
+
+// Stats holds statistics about the releases in a range of tags.
+type Stats struct {
+	// Releases is the number of releases in the range.
+	Releases int `json:"releases"`
+
+	// Commits is the number of commits in the releases.
+	Commits int `json:"commits"`
+
+	// AverageReleaseSize is the mean number of commits per release.
+	AverageReleaseSize float64 `json:"averageReleaseSize"`
+
+	// MedianDaysBetweenReleases is the median time between consecutive
+	// releases, in days, or zero if there are fewer than two releases.
+	MedianDaysBetweenReleases float64 `json:"medianDaysBetweenReleases"`
+
+	// FeatShare and FixShare are the proportions of 'feat' and 'fix'
+	// commits, out of the commits of either type.
+	FeatShare float64 `json:"featShare"`
+	FixShare  float64 `json:"fixShare"`
+
+	// ByType holds the number of commits of each conventional commit type.
+	ByType []Count `json:"byType"`
+
+	// ByAuthor holds the number of commits by each author.
+	ByAuthor []Count `json:"byAuthor"`
+}
+
+// Count is the number of commits for a type or author.
+type Count struct {
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
+
+// Compute returns the statistics for the releases in the commits, as
+// returned by vcs.FetchCommitsByTag. Unreleased commits are not counted.
+// If mailmap is not nil, it is used to de-duplicate authors.
+func Compute(commits []vcs.TagCommits, mailmap *vcs.Mailmap) Stats {
+	byType := make(map[string]int)
+	byAuthor := make(map[string]int)
+	var dates []time.Time
+	stats := Stats{ByType: []Count{}, ByAuthor: []Count{}}
+
+	for _, tagCommits := range commits {
+		if tagCommits.Name == vcs.UnreleasedVersionName {
+			continue
+		}
+		stats.Releases++
+		dates = append(dates, tagCommits.Date)
+
+		for _, detail := range tagCommits.CommitDetails() {
+			stats.Commits++
+			commitType := convcommits.GetType(detail.Message)
+			if commitType == "" {
+				commitType = otherType
+			}
+			byType[commitType]++
+
+			author := detail.Author
+			if mailmap != nil {
+				author, _ = mailmap.Resolve(detail.Author, detail.AuthorEmail)
+			}
+			if author != "" {
+				byAuthor[author]++
+			}
+		}
+	}
+
+	if stats.Releases > 0 {
+		stats.AverageReleaseSize = float64(stats.Commits) / float64(stats.Releases)
+	}
+	stats.MedianDaysBetweenReleases = medianDaysBetween(dates)
+	if featOrFix := byType["feat"] + byType["fix"]; featOrFix > 0 {
+		stats.FeatShare = float64(byType["feat"]) / float64(featOrFix)
+		stats.FixShare = float64(byType["fix"]) / float64(featOrFix)
+	}
+	stats.ByType = sortCounts(byType)
+	stats.ByAuthor = sortCounts(byAuthor)
+	return stats
+}
+
+// medianDaysBetween returns the median interval between consecutive
+// dates, in days, or zero if there are fewer than two dates.
+func medianDaysBetween(dates []time.Time) float64 {
+	if len(dates) < 2 {
+		return 0
+	}
+	sorted := slices.Clone(dates)
+	slices.SortFunc(sorted, func(a, b time.Time) bool {
+		return a.Before(b)
+	})
+	var intervals []float64
+	for i := 1; i < len(sorted); i++ {
+		intervals = append(intervals, sorted[i].Sub(sorted[i-1]).Hours()/24)
+	}
+	sort.Float64s(intervals)
+
+	mid := len(intervals) / 2
+	if len(intervals)%2 == 0 {
+		return (intervals[mid-1] + intervals[mid]) / 2
+	}
+	return intervals[mid]
+}
+
+// sortCounts returns the counts, sorted by descending number of commits,
+// then by name.
+func sortCounts(counts map[string]int) []Count {
+	sorted := []Count{}
+	for name, commits := range counts {
+		sorted = append(sorted, Count{Name: name, Commits: commits})
+	}
+	slices.SortFunc(sorted, func(a, b Count) bool {
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+	return sorted
+}
+
+// Marshal returns the statistics in the given format.
+func (s Stats) Marshal(format Format) (string, error) {
+	switch format {
+	case FormatTable:
+		return s.table(), nil
+
+	case FormatJSON:
+		out, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal stats to JSON: %w", err)
+		}
+		return string(out), nil
+
+	case FormatCSV:
+		return s.csv()
+
+	default:
+		return "", fmt.Errorf("unsupported stats format: %s", format)
+	}
+}
+
+// table returns the statistics as aligned, human-readable tables.
+func (s Stats) table() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "Releases:\t%d\n", s.Releases)
+	_, _ = fmt.Fprintf(w, "Commits:\t%d\n", s.Commits)
+	_, _ = fmt.Fprintf(w, "Average release size:\t%.1f commits\n", s.AverageReleaseSize)
+	_, _ = fmt.Fprintf(w, "Median time between releases:\t%.1f days\n", s.MedianDaysBetweenReleases)
+	_, _ = fmt.Fprintf(w, "Feature share:\t%.1f%%\n", s.FeatShare*100)
+	_, _ = fmt.Fprintf(w, "Fix share:\t%.1f%%\n", s.FixShare*100)
+
+	for _, group := range []struct {
+		heading string
+		counts  []Count
+	}{{"TYPE", s.ByType}, {"AUTHOR", s.ByAuthor}} {
+		_, _ = fmt.Fprintf(w, "\n%s\tCOMMITS\n", group.heading)
+		for _, count := range group.counts {
+			_, _ = fmt.Fprintf(w, "%s\t%d\n", count.Name, count.Commits)
+		}
+	}
+	_ = w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// csv returns the statistics as CSV rows of metric, name and value.
+func (s Stats) csv() (string, error) {
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	records := [][]string{
+		{"metric", "name", "value"},
+		{"releases", "", strconv.Itoa(s.Releases)},
+		{"commits", "", strconv.Itoa(s.Commits)},
+		{"average_release_size", "", formatFloat(s.AverageReleaseSize)},
+		{"median_days_between_releases", "", formatFloat(s.MedianDaysBetweenReleases)},
+		{"feat_share", "", formatFloat(s.FeatShare)},
+		{"fix_share", "", formatFloat(s.FixShare)},
+	}
+	for _, count := range s.ByType {
+		records = append(records, []string{"type", count.Name, strconv.Itoa(count.Commits)})
+	}
+	for _, count := range s.ByAuthor {
+		records = append(records, []string{"author", count.Name, strconv.Itoa(count.Commits)})
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return "", fmt.Errorf("failed to write stats as CSV: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
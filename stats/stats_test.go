@@ -0,0 +1,201 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"github.com/release-tools/since/vcs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCompute(t *testing.T) {
+	mailmap, err := vcs.ParseMailmap(strings.NewReader("Jane Doe <jane@example.com> <jane@laptop.local>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commits := []vcs.TagCommits{
+		{
+			TagMeta: vcs.TagMeta{Name: vcs.UnreleasedVersionName, Date: day(20)},
+			Commits: []string{"feat: not released"},
+		},
+		{
+			TagMeta: vcs.TagMeta{Name: "1.2.0", Date: day(11)},
+			Commits: []string{"feat: add pagination", "fix: correct layout", "update readme"},
+			Details: []vcs.CommitDetail{
+				{Message: "feat: add pagination", Author: "jane", AuthorEmail: "jane@laptop.local"},
+				{Message: "fix: correct layout", Author: "Joe", AuthorEmail: "joe@example.com"},
+				{Message: "update readme", Author: "Jane Doe", AuthorEmail: "jane@example.com"},
+			},
+		},
+		{
+			TagMeta: vcs.TagMeta{Name: "1.1.0", Date: day(3)},
+			Commits: []string{"fix(api): handle errors"},
+			Details: []vcs.CommitDetail{
+				{Message: "fix(api): handle errors", Author: "Joe", AuthorEmail: "joe@example.com"},
+			},
+		},
+		{
+			TagMeta: vcs.TagMeta{Name: "1.0.0", Date: day(1)},
+			Commits: []string{"feat: initial release", "fix: typo"},
+			Details: []vcs.CommitDetail{
+				{Message: "feat: initial release", Author: "Jane Doe", AuthorEmail: "jane@example.com"},
+				{Message: "fix: typo", Author: "Jane Doe", AuthorEmail: "jane@example.com"},
+			},
+		},
+	}
+
+	got := Compute(commits, mailmap)
+	want := Stats{
+		Releases:                  3,
+		Commits:                   6,
+		AverageReleaseSize:        2,
+		MedianDaysBetweenReleases: 5,
+		FeatShare:                 0.4,
+		FixShare:                  0.6,
+		ByType:                    []Count{{Name: "fix", Commits: 3}, {Name: "feat", Commits: 2}, {Name: "other", Commits: 1}},
+		ByAuthor:                  []Count{{Name: "Jane Doe", Commits: 4}, {Name: "Joe", Commits: 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compute() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompute_noReleases(t *testing.T) {
+	got := Compute(nil, nil)
+	want := Stats{ByType: []Count{}, ByAuthor: []Count{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compute() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_medianDaysBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		dates []time.Time
+		want  float64
+	}{
+		{name: "no dates", want: 0},
+		{name: "one date", dates: []time.Time{day(1)}, want: 0},
+		{name: "odd number of intervals", dates: []time.Time{day(10), day(1), day(3), day(4)}, want: 2},
+		{name: "even number of intervals", dates: []time.Time{day(1), day(2), day(5)}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := medianDaysBetween(tt.dates); got != tt.want {
+				t.Errorf("medianDaysBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats_Marshal(t *testing.T) {
+	s := Stats{
+		Releases:                  2,
+		Commits:                   3,
+		AverageReleaseSize:        1.5,
+		MedianDaysBetweenReleases: 7,
+		FeatShare:                 0.5,
+		FixShare:                  0.5,
+		ByType:                    []Count{{Name: "feat", Commits: 1}, {Name: "fix", Commits: 1}},
+		ByAuthor:                  []Count{{Name: "Jane Doe", Commits: 3}},
+	}
+
+	tests := []struct {
+		format  Format
+		want    string
+		wantErr bool
+	}{
+		{
+			format: FormatTable,
+			want: `Releases:                      2
+Commits:                       3
+Average release size:          1.5 commits
+Median time between releases:  7.0 days
+Feature share:                 50.0%
+Fix share:                     50.0%
+
+TYPE  COMMITS
+feat  1
+fix   1
+
+AUTHOR    COMMITS
+Jane Doe  3`,
+		},
+		{
+			format: FormatCSV,
+			want: `metric,name,value
+releases,,2
+commits,,3
+average_release_size,,1.5
+median_days_between_releases,,7
+feat_share,,0.5
+fix_share,,0.5
+type,feat,1
+type,fix,1
+author,Jane Doe,3`,
+		},
+		{
+			format: FormatJSON,
+			want: `{
+  "releases": 2,
+  "commits": 3,
+  "averageReleaseSize": 1.5,
+  "medianDaysBetweenReleases": 7,
+  "featShare": 0.5,
+  "fixShare": 0.5,
+  "byType": [
+    {
+      "name": "feat",
+      "commits": 1
+    },
+    {
+      "name": "fix",
+      "commits": 1
+    }
+  ],
+  "byAuthor": [
+    {
+      "name": "Jane Doe",
+      "commits": 3
+    }
+  ]
+}`,
+		},
+		{
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := s.Marshal(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Marshal() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
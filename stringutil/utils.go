@@ -15,13 +15,29 @@ func ContainsIgnoreCase(orig []string, search ...string) bool {
 	return false
 }
 
-// Unique returns a slice of unique strings.
+// Unique returns a slice of unique strings, compared in a case-insensitive
+// manner, keeping the first occurrence of each.
 func Unique(s []string) []string {
 	var unique []string
-	for _, message := range s {
-		if !ContainsIgnoreCase(unique, message) {
-			unique = append(unique, message)
-		}
+	for _, i := range UniqueIndices(s) {
+		unique = append(unique, s[i])
 	}
 	return unique
 }
+
+// UniqueIndices returns the indices of the first occurrence of each unique
+// string in the slice, compared in a case-insensitive manner. It runs in
+// linear time, using a set of the strings seen so far.
+func UniqueIndices(s []string) []int {
+	var indices []int
+	seen := make(map[string]struct{}, len(s))
+	for i, str := range s {
+		key := strings.ToLower(str)
+		if _, found := seen[key]; found {
+			continue
+		}
+		seen[key] = struct{}{}
+		indices = append(indices, i)
+	}
+	return indices
+}
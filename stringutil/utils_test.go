@@ -1,6 +1,7 @@
 package stringutil
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -93,3 +94,22 @@ func TestUnique(t *testing.T) {
 		})
 	}
 }
+
+func TestUniqueIndices(t *testing.T) {
+	got := UniqueIndices([]string{"foo", "bar", "FOO", "baz", "Bar"})
+	want := []int{0, 1, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UniqueIndices() = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkUnique(b *testing.B) {
+	messages := make([]string, 5000)
+	for i := range messages {
+		messages[i] = fmt.Sprintf("fix: change %d", i%2500)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Unique(messages)
+	}
+}
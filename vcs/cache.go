@@ -0,0 +1,456 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// CacheDir is the directory of the history cache, relative to the git
+// directory of the repository.
+const CacheDir = "since"
+
+// cacheVersion is the version of the cache files. Files written by
+// another version are ignored.
+const cacheVersion = 1
+
+const (
+	cacheTagsFile   = "tags.json"
+	cachePatchFile  = "patch-ids.json"
+	cacheRangesDir  = "ranges"
+	cacheRangeExt   = ".json"
+	cacheRootMarker = "root"
+)
+
+// historyCache stores the resolved tags and the parsed commits of each
+// released range of the history in the git directory, so that repeated
+// runs only read the commits since the last release.
+//
+// Every entry is keyed by hashes: a tag is reused only while its ref
+// points to the same object, and a range is keyed by the hashes of the
+// commits at either end, so an entry can never describe different
+// history. Any error reading the cache is logged and the history is
+// read from the repository instead.
+type historyCache struct {
+	fs billy.Filesystem
+
+	// tags holds the cached tag index, keyed by tag name.
+	tags map[string]cachedTag
+}
+
+// cachedTag is a tag resolved to the commit it points to.
+type cachedTag struct {
+	// Ref is the hash the tag ref points to, which is the hash of the tag
+	// object for an annotated tag.
+	Ref    string    `json:"ref"`
+	Commit string    `json:"commit"`
+	Date   time.Time `json:"date"`
+}
+
+type cachedTagsFile struct {
+	Version int                  `json:"version"`
+	Tags    map[string]cachedTag `json:"tags"`
+}
+
+// cachedPatchIDsFile holds the patch ID of each commit compared when
+// detecting cherry-picks, keyed by the commit hash.
+type cachedPatchIDsFile struct {
+	Version  int               `json:"version"`
+	PatchIDs map[string]string `json:"patchIds"`
+}
+
+// cachedRange holds the commits of a released range, in the order they
+// are walked, from the tagged commit up to, but not including, the next
+// tagged commit, if any.
+type cachedRange struct {
+	Version int            `json:"version"`
+	Commits []cachedCommit `json:"commits"`
+
+	// Next is the hash of the tagged commit the walk continues from, or
+	// empty if the range ends the walk.
+	Next string `json:"next,omitempty"`
+}
+
+type cachedCommit struct {
+	Hash      string           `json:"hash"`
+	Parents   []string         `json:"parents,omitempty"`
+	Author    object.Signature `json:"author"`
+	Committer object.Signature `json:"committer"`
+	Message   string           `json:"message"`
+}
+
+// openHistoryCache returns the cache of the repository, or nil if the
+// repository is not stored on disk.
+func openHistoryCache(r *git.Repository) *historyCache {
+	storage, ok := r.Storer.(*filesystem.Storage)
+	if !ok {
+		logrus.Debugf("history cache is not supported for this repository")
+		return nil
+	}
+	fs, err := storage.Filesystem().Chroot(CacheDir)
+	if err != nil {
+		logrus.Debugf("failed to open history cache: %v", err)
+		return nil
+	}
+	c := &historyCache{fs: fs, tags: make(map[string]cachedTag)}
+
+	var file cachedTagsFile
+	if c.read(cacheTagsFile, &file) && file.Version == cacheVersion {
+		c.tags = file.Tags
+	}
+	return c
+}
+
+// resolveTags returns a map of the hash of each tagged commit to the tag
+// metadata, like listAllTags, resolving only the tags that are not in the
+// cache, or whose ref has moved. The cache is updated if any tag changed.
+func (c *historyCache) resolveTags(r *git.Repository) (map[string]*TagMeta, error) {
+	tags := make(map[string]*TagMeta)
+	current := make(map[string]cachedTag)
+	changed := false
+
+	tagRefs, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+	err = tagRefs.ForEach(func(t *plumbing.Reference) error {
+		name := t.Name().Short()
+		cached, found := c.tags[name]
+		if !found || cached.Ref != t.Hash().String() {
+			logrus.Tracef("resolving tag %s", name)
+			commitHash, err := getCommitHashForTag(t, r)
+			if err != nil {
+				return err
+			}
+			commit, err := r.CommitObject(commitHash)
+			if err != nil {
+				return err
+			}
+			cached = cachedTag{Ref: t.Hash().String(), Commit: commitHash.String(), Date: commit.Committer.When}
+			changed = true
+		}
+		current[name] = cached
+		tags[cached.Commit] = &TagMeta{Name: name, Date: cached.Date}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed || len(current) != len(c.tags) {
+		c.tags = current
+		c.write(cacheTagsFile, cachedTagsFile{Version: cacheVersion, Tags: current})
+		c.pruneRanges(tags)
+	}
+	return tags, nil
+}
+
+// getRange returns the cached range starting at the given commit, walked
+// with the given stop commit, if there is one.
+func (c *historyCache) getRange(start plumbing.Hash, stop *plumbing.Hash) (*cachedRange, bool) {
+	var cached cachedRange
+	if !c.read(rangeFile(start, stop), &cached) || cached.Version != cacheVersion {
+		return nil, false
+	}
+	return &cached, true
+}
+
+// putRange stores the range starting at the given commit, walked with the
+// given stop commit.
+func (c *historyCache) putRange(start plumbing.Hash, stop *plumbing.Hash, commits []cachedCommit, next string) {
+	c.write(rangeFile(start, stop), cachedRange{Version: cacheVersion, Commits: commits, Next: next})
+}
+
+// getPatchIDs returns the cached patch IDs, keyed by commit hash.
+func (c *historyCache) getPatchIDs() map[string]string {
+	var file cachedPatchIDsFile
+	if !c.read(cachePatchFile, &file) || file.Version != cacheVersion {
+		return nil
+	}
+	return file.PatchIDs
+}
+
+// putPatchIDs stores the patch IDs, keyed by commit hash.
+func (c *historyCache) putPatchIDs(patchIDs map[string]string) {
+	c.write(cachePatchFile, cachedPatchIDsFile{Version: cacheVersion, PatchIDs: patchIDs})
+}
+
+// pruneRanges removes the cached ranges that do not start at a tagged
+// commit, such as those of tags that have been moved or deleted.
+func (c *historyCache) pruneRanges(tags map[string]*TagMeta) {
+	files, err := c.fs.ReadDir(cacheRangesDir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		start, _, _ := strings.Cut(strings.TrimSuffix(file.Name(), cacheRangeExt), "-")
+		if tags[start] == nil {
+			logrus.Tracef("removing cached range %s", file.Name())
+			if err := c.fs.Remove(path.Join(cacheRangesDir, file.Name())); err != nil {
+				logrus.Debugf("failed to remove cached range %s: %v", file.Name(), err)
+			}
+		}
+	}
+}
+
+// rangeFile returns the path of the cache file of a range.
+func rangeFile(start plumbing.Hash, stop *plumbing.Hash) string {
+	stopKey := cacheRootMarker
+	if stop != nil {
+		stopKey = stop.String()
+	}
+	return path.Join(cacheRangesDir, start.String()+"-"+stopKey+cacheRangeExt)
+}
+
+// read decodes the cache file into v, returning false if the file does
+// not exist or cannot be read.
+func (c *historyCache) read(filename string, v any) bool {
+	file, err := c.fs.Open(filename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Debugf("failed to open cache file %s: %v", filename, err)
+		}
+		return false
+	}
+	defer file.Close()
+	if err := json.NewDecoder(file).Decode(v); err != nil {
+		logrus.Debugf("ignoring invalid cache file %s: %v", filename, err)
+		return false
+	}
+	return true
+}
+
+// write encodes v to the cache file, replacing it atomically, so that a
+// concurrent reader never sees a partially written file.
+func (c *historyCache) write(filename string, v any) {
+	if err := c.writeFile(filename, v); err != nil {
+		logrus.Debugf("failed to write cache file %s: %v", filename, err)
+	}
+}
+
+func (c *historyCache) writeFile(filename string, v any) error {
+	if err := c.fs.MkdirAll(path.Dir(filename), 0755); err != nil {
+		return err
+	}
+	tmp, err := c.fs.TempFile(path.Dir(filename), path.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(tmp).Encode(v); err != nil {
+		_ = tmp.Close()
+		_ = c.fs.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = c.fs.Remove(tmp.Name())
+		return err
+	}
+	if err := c.fs.Rename(tmp.Name(), filename); err != nil {
+		_ = c.fs.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace cache file: %w", err)
+	}
+	return nil
+}
+
+// newCachedCommit returns the cache entry of the commit.
+func newCachedCommit(commit *object.Commit) cachedCommit {
+	parents := make([]string, len(commit.ParentHashes))
+	for i, parent := range commit.ParentHashes {
+		parents[i] = parent.String()
+	}
+	return cachedCommit{
+		Hash:      commit.Hash.String(),
+		Parents:   parents,
+		Author:    commit.Author,
+		Committer: commit.Committer,
+		Message:   commit.Message,
+	}
+}
+
+// commit returns the commit of the cache entry. It is not backed by the
+// repository, so only its hash, parent hashes, signatures and message
+// are set.
+func (c cachedCommit) commit() *object.Commit {
+	parents := make([]plumbing.Hash, len(c.Parents))
+	for i, parent := range c.Parents {
+		parents[i] = plumbing.NewHash(parent)
+	}
+	return &object.Commit{
+		Hash:         plumbing.NewHash(c.Hash),
+		Author:       c.Author,
+		Committer:    c.Committer,
+		Message:      c.Message,
+		ParentHashes: parents,
+	}
+}
+
+// historyWalker walks the commits reachable from start but not stop,
+// like walkCommits, in ranges that each start at a tagged commit. Once
+// the walk reaches a tagged commit that is the only commit left to walk,
+// the rest of the history is that of the tagged commit alone, so the
+// walk restarts from it, reading the range from the cache if there is
+// one, or storing it in the cache once it has been walked.
+type historyWalker struct {
+	index commitgraph.CommitNodeIndex
+	cache *historyCache
+	tags  map[string]*TagMeta
+	stop  *plumbing.Hash
+
+	// cutoff is the earliest commit time walked, or zero to walk the whole
+	// history. A range walked with a cutoff may be incomplete, so it is
+	// not stored in the cache.
+	cutoff time.Time
+
+	// start is the first commit of the current range.
+	start plumbing.Hash
+
+	// walker walks the current range, if it is not cached.
+	walker *commitWalker
+	// walked holds the commits of the current range walked so far.
+	walked []cachedCommit
+
+	// cached holds the commits of the current range, if it is cached.
+	cached *cachedRange
+	// pos is the position of the next commit in the cached range.
+	pos int
+}
+
+// walkHistory returns an iterator over the commits reachable from start,
+// but not from stop, newest commit first, reading and storing the released
+// ranges of the history in the cache. If the cache is nil, it is the same
+// as walkCommits. If the cutoff is not zero, commits committed before it
+// are not walked, like 'git log --since'.
+func walkHistory(
+	index commitgraph.CommitNodeIndex,
+	cache *historyCache,
+	tags map[string]*TagMeta,
+	start plumbing.Hash,
+	stop *plumbing.Hash,
+	cutoff time.Time,
+) (object.CommitIter, error) {
+	if cache == nil {
+		walker, err := walkCommits(index, start, stop)
+		if err != nil {
+			return nil, err
+		}
+		walker.cutoff = cutoff
+		return walker, nil
+	}
+	w := &historyWalker{index: index, cache: cache, tags: tags, stop: stop, cutoff: cutoff}
+	if err := w.startRange(start); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// startRange starts walking the range from the given commit, from the
+// cache if the commit is tagged and its range has been cached.
+func (w *historyWalker) startRange(start plumbing.Hash) error {
+	w.start = start
+	w.walker = nil
+	w.walked = nil
+	w.cached = nil
+	w.pos = 0
+
+	if w.isTagged(start) {
+		if cached, found := w.cache.getRange(start, w.stop); found {
+			logrus.Tracef("read %d commits after %s from cache", len(cached.Commits), start)
+			w.cached = cached
+			return nil
+		}
+	}
+	walker, err := walkCommits(w.index, start, w.stop)
+	if err != nil {
+		return err
+	}
+	walker.cutoff = w.cutoff
+	w.walker = walker
+	return nil
+}
+
+// Next returns the next commit that is reachable from the start commit,
+// but not the stop commit, or io.EOF when there are none.
+func (w *historyWalker) Next() (*object.Commit, error) {
+	for {
+		if w.cached != nil {
+			if w.pos < len(w.cached.Commits) {
+				w.pos++
+				return w.cached.Commits[w.pos-1].commit(), nil
+			}
+			if w.cached.Next == "" {
+				return nil, io.EOF
+			}
+			if err := w.startRange(plumbing.NewHash(w.cached.Next)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		c, err := w.walker.Next()
+		if err == io.EOF {
+			w.storeRange("")
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c.Hash != w.start && w.walker.alone && w.isTagged(c.Hash) {
+			w.storeRange(c.Hash.String())
+			if err := w.startRange(c.Hash); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		w.walked = append(w.walked, newCachedCommit(c))
+		return c, nil
+	}
+}
+
+// storeRange stores the current range in the cache, if it starts at a
+// tagged commit and was walked without a cutoff.
+func (w *historyWalker) storeRange(next string) {
+	if w.isTagged(w.start) && w.cutoff.IsZero() {
+		logrus.Tracef("caching %d commits after %s", len(w.walked), w.start)
+		w.cache.putRange(w.start, w.stop, w.walked, next)
+	}
+}
+
+func (w *historyWalker) isTagged(hash plumbing.Hash) bool {
+	return w.tags[hash.String()] != nil
+}
+
+// ForEach calls the callback for each commit, until the callback returns
+// an error, or storer.ErrStop to end the walk early.
+func (w *historyWalker) ForEach(cb func(*object.Commit) error) error {
+	return forEachCommit(w, cb)
+}
+
+func (w *historyWalker) Close() {}
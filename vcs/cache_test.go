@@ -0,0 +1,187 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"encoding/json"
+	"github.com/go-git/go-git/v5"
+	"github.com/release-tools/since/cfg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFetchCommitsByTag_cache(t *testing.T) {
+	tests := []struct {
+		name     string
+		afterTag string
+		modify   func(t *testing.T, repoDir string)
+	}{
+		{
+			name: "cold and warm cache",
+		},
+		{
+			name:     "after tag",
+			afterTag: "1.0.0",
+		},
+		{
+			name: "moved tag",
+			modify: func(t *testing.T, repoDir string) {
+				r, err := git.PlainOpen(repoDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tag, err := r.Tag("1.2.0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				commit, err := r.CommitObject(tag.Hash())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := r.DeleteTag("1.2.0"); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := r.CreateTag("1.2.0", commit.ParentHashes[0], nil); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "deleted tag",
+			modify: func(t *testing.T, repoDir string) {
+				r, err := git.PlainOpen(repoDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := r.DeleteTag("1.2.0"); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "invalid cache file",
+			modify: func(t *testing.T, repoDir string) {
+				if err := os.WriteFile(filepath.Join(repoDir, ".git", CacheDir, cacheTagsFile), []byte("{"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := createWalkTestRepo(t, 30)
+			cached := CommitConfig{UseCache: true}
+			if _, _, err := FetchCommitsByTag(cfg.SinceConfig{}, cached, repoDir, "", tt.afterTag); err != nil {
+				t.Fatalf("FetchCommitsByTag() error = %v", err)
+			}
+			if tt.modify != nil {
+				tt.modify(t, repoDir)
+			}
+
+			want, _, err := FetchCommitsByTag(cfg.SinceConfig{}, CommitConfig{}, repoDir, "", tt.afterTag)
+			if err != nil {
+				t.Fatalf("FetchCommitsByTag() error = %v", err)
+			}
+			got, _, err := FetchCommitsByTag(cfg.SinceConfig{}, cached, repoDir, "", tt.afterTag)
+			if err != nil {
+				t.Fatalf("FetchCommitsByTag() error = %v", err)
+			}
+			assertSameHistory(t, *got, *want)
+		})
+	}
+}
+
+func TestFetchCommitsByTag_readsCache(t *testing.T) {
+	repoDir := createWalkTestRepo(t, 30)
+	cached := CommitConfig{UseCache: true}
+	if _, _, err := FetchCommitsByTag(cfg.SinceConfig{}, cached, repoDir, "", ""); err != nil {
+		t.Fatalf("FetchCommitsByTag() error = %v", err)
+	}
+
+	// rewrite the cached messages, to check the commits are read from the cache
+	ranges, err := filepath.Glob(filepath.Join(repoDir, ".git", CacheDir, cacheRangesDir, "*"+cacheRangeExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 2 {
+		t.Fatalf("cached %d ranges, want 2", len(ranges))
+	}
+	for _, file := range ranges {
+		var r cachedRange
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &r); err != nil {
+			t.Fatal(err)
+		}
+		for i := range r.Commits {
+			r.Commits[i].Message = "cached: " + r.Commits[i].Message
+		}
+		if data, err = json.Marshal(r); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commits, _, err := FetchCommitsByTag(cfg.SinceConfig{}, cached, repoDir, "", "")
+	if err != nil {
+		t.Fatalf("FetchCommitsByTag() error = %v", err)
+	}
+	for _, tagCommits := range *commits {
+		for _, message := range tagCommits.Commits {
+			fromCache := strings.HasPrefix(message, "cached: ")
+			if wantCached := tagCommits.Name != UnreleasedVersionName; fromCache != wantCached {
+				t.Errorf("commit %q of %s read from cache = %v, want %v", message, tagCommits.Name, fromCache, wantCached)
+			}
+		}
+	}
+}
+
+// assertSameHistory fails the test if the commits of each tag differ,
+// ignoring the date of the unreleased commits.
+func assertSameHistory(t *testing.T, got []TagCommits, want []TagCommits) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d tags, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name {
+			t.Errorf("tag %d = %s, want %s", i, got[i].Name, want[i].Name)
+		}
+		if want[i].Name != UnreleasedVersionName && !got[i].Date.Equal(want[i].Date) {
+			t.Errorf("tag %s date = %v, want %v", want[i].Name, got[i].Date, want[i].Date)
+		}
+		if !reflect.DeepEqual(got[i].Commits, want[i].Commits) {
+			t.Errorf("tag %s commits = %v, want %v", want[i].Name, got[i].Commits, want[i].Commits)
+		}
+		if len(got[i].Details) != len(want[i].Details) {
+			t.Fatalf("tag %s has %d details, want %d", want[i].Name, len(got[i].Details), len(want[i].Details))
+		}
+		for j := range want[i].Details {
+			g, w := got[i].Details[j], want[i].Details[j]
+			if g.Hash != w.Hash || g.Author != w.Author || !g.Date.Equal(w.Date) || !g.CommitDate.Equal(w.CommitDate) || g.Body != w.Body {
+				t.Errorf("tag %s commit %d = %+v, want %+v", want[i].Name, j, g, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var cherryPickTrailerRegex = regexp.MustCompile(`\(cherry picked from commit ([0-9a-f]{40})\)`)
+
+// releaseIndex records when the commits released on other branches were
+// first released, to detect commits that are copies of a commit already
+// released under another tag, such as cherry-picks and backports.
+//
+// The index is built lazily, the first time a commit is checked, and only
+// holds the commits reachable from a tag, but not from the start of the
+// walk, so only the history of the other branches since they diverged is
+// read. The patch ID of each commit compared is stored in the history
+// cache, if there is one, as a commit never changes.
+type releaseIndex struct {
+	storer storer.EncodedObjectStorer
+	index  commitgraph.CommitNodeIndex
+	cache  *historyCache
+	tags   map[string]*TagMeta
+	start  plumbing.Hash
+
+	// released maps the hash of each commit released on another branch to
+	// the date of the earliest tag that contains it, or is nil until the
+	// index is built.
+	released map[plumbing.Hash]time.Time
+
+	// candidates holds the commits released on other branches, newest first.
+	candidates []*object.Commit
+
+	// byPatchID maps the patch ID of each candidate to the earliest
+	// released candidate with that patch, or is nil until the patch IDs
+	// of the candidates are computed.
+	byPatchID map[string]releasedCommit
+
+	// patchIDs caches the patch ID of each commit compared.
+	patchIDs map[plumbing.Hash]string
+
+	// changed is true if a patch ID was computed that is not in the cache.
+	changed bool
+}
+
+// releasedCommit is a commit and the date it was first released.
+type releasedCommit struct {
+	hash plumbing.Hash
+	date time.Time
+}
+
+// newReleaseIndex returns an index of the commits released under the tags,
+// on branches other than the history of the start commit, reading any
+// patch IDs stored in the cache.
+func newReleaseIndex(
+	r *git.Repository,
+	index commitgraph.CommitNodeIndex,
+	cache *historyCache,
+	allTags map[string]*TagMeta,
+	start plumbing.Hash,
+) *releaseIndex {
+	idx := &releaseIndex{
+		storer:   r.Storer,
+		index:    index,
+		cache:    cache,
+		tags:     allTags,
+		start:    start,
+		patchIDs: make(map[plumbing.Hash]string),
+	}
+	if cache != nil {
+		for hash, id := range cache.getPatchIDs() {
+			idx.patchIDs[plumbing.NewHash(hash)] = id
+		}
+	}
+	return idx
+}
+
+// load walks the history of the tags that is not reachable from the start
+// commit, recording the earliest tag that contains each commit. Commits
+// are walked in commit time order, so the date of each commit is known
+// before those of its parents.
+func (idx *releaseIndex) load() error {
+	if idx.released != nil {
+		return nil
+	}
+	idx.released = make(map[plumbing.Hash]time.Time)
+
+	dates := make(map[plumbing.Hash]time.Time)
+	starts := make([]plumbing.Hash, 0, len(idx.tags))
+	for hash, tag := range idx.tags {
+		commitHash := plumbing.NewHash(hash)
+		starts = append(starts, commitHash)
+		if date, found := dates[commitHash]; !found || tag.Date.Before(date) {
+			dates[commitHash] = tag.Date
+		}
+	}
+	commits, err := walkCommitsFrom(idx.index, starts, []plumbing.Hash{idx.start})
+	if err != nil {
+		return fmt.Errorf("failed to walk history of tags: %w", err)
+	}
+	err = commits.ForEach(func(c *object.Commit) error {
+		date := dates[c.Hash]
+		idx.released[c.Hash] = date
+		idx.candidates = append(idx.candidates, c)
+		for _, parent := range c.ParentHashes {
+			if parentDate, found := dates[parent]; !found || date.Before(parentDate) {
+				dates[parent] = date
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk history of tags: %w", err)
+	}
+	logrus.Tracef("indexed %d commits released on other branches", len(idx.released))
+	return nil
+}
+
+// loadPatchIDs computes the patch ID of each candidate, recording the
+// earliest released candidate with each patch.
+func (idx *releaseIndex) loadPatchIDs() error {
+	if idx.byPatchID != nil {
+		return nil
+	}
+	idx.byPatchID = make(map[string]releasedCommit)
+	for _, candidate := range idx.candidates {
+		id, err := idx.patchID(candidate)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			continue
+		}
+		date := idx.released[candidate.Hash]
+		if existing, found := idx.byPatchID[id]; !found || date.Before(existing.date) {
+			idx.byPatchID[id] = releasedCommit{hash: candidate.Hash, date: date}
+		}
+	}
+	return nil
+}
+
+// isReleasedCopy returns true if the commit is a copy of a different commit
+// that was released on another branch before the given date. A commit is
+// a copy if its message has a '(cherry picked from commit ...)' trailer
+// naming the other commit, or if it has the same patch ID.
+func (idx *releaseIndex) isReleasedCopy(c *object.Commit, before time.Time) (bool, error) {
+	if err := idx.load(); err != nil {
+		return false, err
+	}
+	if len(idx.candidates) == 0 {
+		return false, nil
+	}
+
+	for _, m := range cherryPickTrailerRegex.FindAllStringSubmatch(c.Message, -1) {
+		hash := plumbing.NewHash(m[1])
+		if date, found := idx.released[hash]; found && hash != c.Hash && date.Before(before) {
+			logrus.Tracef("commit %s was cherry-picked from released commit %s", c.Hash, m[1])
+			return true, nil
+		}
+	}
+
+	id, err := idx.patchID(c)
+	if err != nil || id == "" {
+		return false, err
+	}
+	if err := idx.loadPatchIDs(); err != nil {
+		return false, err
+	}
+	original, found := idx.byPatchID[id]
+	if found && original.hash != c.Hash && original.date.Before(before) {
+		logrus.Tracef("commit %s has the same patch as released commit %s", c.Hash, original.hash)
+		return true, nil
+	}
+	return false, nil
+}
+
+// save stores the patch IDs computed in the cache, if there is one.
+func (idx *releaseIndex) save() {
+	if idx.cache == nil || !idx.changed {
+		return
+	}
+	patchIDs := make(map[string]string, len(idx.patchIDs))
+	for hash, id := range idx.patchIDs {
+		patchIDs[hash.String()] = id
+	}
+	idx.cache.putPatchIDs(patchIDs)
+}
+
+// patchID returns an identifier for the changes made by a commit, which is
+// the same for commits that make the same changes to the same files, like
+// 'git patch-id'. Line numbers, context lines and whitespace are ignored.
+// Commits without exactly one parent, or without any changes, have no
+// patch ID.
+func (idx *releaseIndex) patchID(c *object.Commit) (string, error) {
+	if id, found := idx.patchIDs[c.Hash]; found {
+		return id, nil
+	}
+	if c.NumParents() != 1 {
+		return "", nil
+	}
+	// the commit may have been read from the history cache, so is not
+	// backed by the repository
+	c, err := object.GetCommit(idx.storer, c.Hash)
+	if err != nil {
+		return "", err
+	}
+	parent, err := c.Parent(0)
+	if err != nil {
+		return "", err
+	}
+	patch, err := parent.Patch(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff commit %s: %w", c.Hash, err)
+	}
+	if len(patch.FilePatches()) == 0 {
+		// every empty commit would otherwise have the same patch ID
+		idx.patchIDs[c.Hash] = ""
+		idx.changed = true
+		return "", nil
+	}
+
+	h := sha1.New()
+	for _, filePatch := range patch.FilePatches() {
+		from, to := filePatch.Files()
+		for _, file := range []diff.File{from, to} {
+			if file != nil {
+				h.Write([]byte(file.Path()))
+			}
+			h.Write([]byte{0})
+		}
+		for _, chunk := range filePatch.Chunks() {
+			var op string
+			switch chunk.Type() {
+			case diff.Add:
+				op = "+"
+			case diff.Delete:
+				op = "-"
+			default:
+				continue
+			}
+			for _, line := range strings.Split(chunk.Content(), "\n") {
+				h.Write([]byte(op + removeWhitespace(line) + "\n"))
+			}
+		}
+	}
+	id := hex.EncodeToString(h.Sum(nil))
+	idx.patchIDs[c.Hash] = id
+	idx.changed = true
+	return id, nil
+}
+
+// removeWhitespace returns the string without any whitespace.
+func removeWhitespace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,200 @@
+package vcs
+
+import (
+	"encoding/json"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/release-tools/since/cfg"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFetchCommitsByTag_skipCherryPicks(t *testing.T) {
+	repoDir := createCherryPickTestRepo(t)
+
+	tests := []struct {
+		name            string
+		skipCherryPicks bool
+		want            []string
+		wantCherryPicks int
+	}{
+		{
+			name: "all commits",
+			want: []string{"fix: reject negative sizes", "fix: handle empty input", "fix: correct typo", "feat: add feature"},
+		},
+		{
+			name:            "skip cherry-picks",
+			skipCherryPicks: true,
+			want:            []string{"feat: add feature"},
+			wantCherryPicks: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commitCfg := CommitConfig{SkipCherryPicks: tt.skipCherryPicks}
+			commits, stats, err := FetchCommitsByTag(cfg.SinceConfig{}, commitCfg, repoDir, "", "1.0.0")
+			if err != nil {
+				t.Fatalf("FetchCommitsByTag() error = %v", err)
+			}
+			if got := FlattenCommits(commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FetchCommitsByTag() = %v, want %v", got, tt.want)
+			}
+			if stats.CherryPicks != tt.wantCherryPicks {
+				t.Errorf("FetchCommitsByTag() cherry-picks = %v, want %v", stats.CherryPicks, tt.wantCherryPicks)
+			}
+		})
+	}
+}
+
+// createCherryPickTestRepo creates a repository with a release branch
+// holding three fixes, released as 1.0.1, which are then copied onto the
+// main branch: one with the same subject and patch, one with a
+// '(cherry picked from commit ...)' trailer, and one with the same patch
+// under another subject.
+func createCherryPickTestRepo(t *testing.T) string {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	commit := func(file string, content string, message string, offset time.Duration) plumbing.Hash {
+		if err := os.WriteFile(path.Join(repoDir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Add(file); err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: "user", Email: "user@example.com", When: base.Add(offset)}
+		hash, err := w.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	tag := func(name string, hash plumbing.Hash) {
+		if _, err := repo.CreateTag(name, hash, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	initial := commit("README.md", "hello\n", "chore: initial commit", 0)
+	tag("1.0.0", initial)
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release-1.x"), Create: true, Hash: initial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit("README.md", "hello, world\n", "fix: correct typo", time.Minute)
+	backport := commit("input.txt", "empty input is ignored\n", "fix: handle empty input on 1.x", 2*time.Minute)
+	tag("1.0.1", commit("size.txt", "negative sizes are rejected\n", "fix: validate sizes", 150*time.Second))
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.Master})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit("feature.txt", "feature\n", "feat: add feature", 3*time.Minute)
+	commit("README.md", "hello, world\n", "fix: correct typo", 4*time.Minute)
+	commit("input.txt", "empty input is ignored\n", "fix: handle empty input\n\n(cherry picked from commit "+backport.String()+")", 5*time.Minute)
+	commit("size.txt", "negative sizes are rejected\n", "fix: reject negative sizes", 6*time.Minute)
+	return repoDir
+}
+
+func TestFetchCommitsByTag_emptyCommitsAreNotCherryPicks(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(repoDir, "README.md"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("README.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	commit := func(message string, offset time.Duration) plumbing.Hash {
+		sig := &object.Signature{Name: "user", Email: "user@example.com", When: base.Add(offset)}
+		hash, err := w.Commit(message, &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	initial := commit("chore: initial commit", 0)
+	if _, err := repo.CreateTag("1.0.0", initial, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release-1.x"), Create: true, Hash: initial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateTag("1.0.1", commit("chore: trigger CI", time.Minute), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.Master})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit("chore: rebuild the docs", 2*time.Minute)
+
+	commits, stats, err := FetchCommitsByTag(cfg.SinceConfig{}, CommitConfig{SkipCherryPicks: true}, repoDir, "", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchCommitsByTag() error = %v", err)
+	}
+	if got := FlattenCommits(commits); !reflect.DeepEqual(got, []string{"chore: rebuild the docs"}) {
+		t.Errorf("FetchCommitsByTag() = %v, want [chore: rebuild the docs]", got)
+	}
+	if stats.CherryPicks != 0 {
+		t.Errorf("FetchCommitsByTag() cherry-picks = %v, want 0", stats.CherryPicks)
+	}
+}
+
+func TestFetchCommitsByTag_cachesPatchIDs(t *testing.T) {
+	repoDir := createCherryPickTestRepo(t)
+	commitCfg := CommitConfig{SkipCherryPicks: true, UseCache: true}
+	if _, _, err := FetchCommitsByTag(cfg.SinceConfig{}, commitCfg, repoDir, "", "1.0.0"); err != nil {
+		t.Fatalf("FetchCommitsByTag() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path.Join(repoDir, ".git", CacheDir, cachePatchFile))
+	if err != nil {
+		t.Fatalf("patch IDs not cached: %v", err)
+	}
+	var file cachedPatchIDsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	// the three released fixes, and the commits on the main branch other
+	// than the one with a cherry-pick trailer
+	if len(file.PatchIDs) != 6 {
+		t.Errorf("cached %d patch IDs, want 6", len(file.PatchIDs))
+	}
+
+	commits, stats, err := FetchCommitsByTag(cfg.SinceConfig{}, commitCfg, repoDir, "", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchCommitsByTag() error = %v", err)
+	}
+	if got := FlattenCommits(commits); !reflect.DeepEqual(got, []string{"feat: add feature"}) {
+		t.Errorf("FetchCommitsByTag() with cached patch IDs = %v, want [feat: add feature]", got)
+	}
+	if stats.CherryPicks != 3 {
+		t.Errorf("FetchCommitsByTag() cherry-picks = %v, want 3", stats.CherryPicks)
+	}
+}
@@ -21,8 +21,8 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/release-tools/since/cfg"
+	"github.com/release-tools/since/convcommits"
 	"github.com/release-tools/since/stringutil"
 	"github.com/sirupsen/logrus"
 	"regexp"
@@ -32,9 +32,32 @@ import (
 
 const UnreleasedVersionName = "Unreleased"
 
+// ChangelogTrailer is the trailer in the body of a commit message that
+// replaces the text of its changelog entry, or, with the value
+// ChangelogSkipValue, leaves the commit out of the changelog.
+const ChangelogTrailer = "Changelog"
+
+// ChangelogSkipValue is the value of the Changelog trailer that leaves
+// the commit out of the changelog, and of the version calculation.
+const ChangelogSkipValue = "skip"
+
 type CommitConfig struct {
 	ExcludeTagCommits bool
 	UniqueOnly        bool
+
+	// SkipCherryPicks excludes commits that copy a commit already released
+	// under an earlier tag, such as cherry-picks and backports on another
+	// release line, so they are not listed twice.
+	SkipCherryPicks bool
+
+	// UseCache reads and stores the resolved tags and the commits of each
+	// released range in a cache in the git directory, so that repeated runs
+	// only read the commits since the last release.
+	UseCache bool
+
+	// Dates restricts the commits to those dated within a range, within
+	// the range of tags.
+	Dates DateFilter
 }
 
 // FilterStats captures how many commits were considered when fetching
@@ -44,6 +67,19 @@ type CommitConfig struct {
 type FilterStats struct {
 	Considered int
 	Excluded   int
+
+	// CherryPicks is the number of commits skipped as copies of a commit
+	// that was already released.
+	CherryPicks int
+
+	// OutsideDates is the number of commits skipped as they are not dated
+	// within the configured date range. Commits committed before the start
+	// of the range are not read, so are not counted.
+	OutsideDates int
+
+	// Skipped is the number of commits left out by a 'Changelog: skip'
+	// trailer.
+	Skipped int
 }
 
 // FetchCommitMessages returns a slice of commit messages between the given tags.
@@ -84,7 +120,7 @@ func FetchCommitsByTag(
 	if logrus.IsLevelEnabled(logrus.TraceLevel) {
 		logrus.Tracef("commits by tag: %v", commits)
 	} else {
-		logrus.Debugf("fetched %d tags (considered %d commits, excluded %d)", len(*commits), stats.Considered, stats.Excluded)
+		logrus.Debugf("fetched %d tags (considered %d commits, excluded %d, skipped %d by trailer, %d cherry-picks, %d outside dates)", len(*commits), stats.Considered, stats.Excluded, stats.Skipped, stats.CherryPicks, stats.OutsideDates)
 	}
 	return commits, stats, nil
 }
@@ -153,20 +189,54 @@ func fetchCommitsBetween(
 		}
 	}
 
-	allTags, err := listAllTags(r)
+	var cache *historyCache
+	if commitCfg.UseCache {
+		cache = openHistoryCache(r)
+	}
+	var allTags map[string]*TagMeta
+	if cache != nil {
+		allTags, err = cache.resolveTags(r)
+	} else {
+		allTags, err = listAllTags(r)
+	}
 	if err != nil {
 		return nil, FilterStats{}, err
 	}
 
-	commits, err := r.Log(&git.LogOptions{})
+	start := beforeCommit
+	if start == nil {
+		head, err := r.Head()
+		if err != nil {
+			return nil, FilterStats{}, err
+		}
+		if start, err = r.CommitObject(head.Hash()); err != nil {
+			return nil, FilterStats{}, err
+		}
+	}
+	var stop *plumbing.Hash
+	if afterCommit != nil {
+		stop = &afterCommit.Hash
+	}
+	index, closer := openCommitNodeIndex(r)
+	defer closer.Close()
+	// a commit is written before it is committed, so the history committed
+	// before the since date is not walked, whichever date is filtered
+	commits, err := walkHistory(index, cache, allTags, start.Hash, stop, commitCfg.Dates.Since)
 	if err != nil {
 		return nil, FilterStats{}, err
 	}
 
-	tagCommits, stats, err := processCommits(commitCfg, beforeCommit, afterCommit, commits, allTags, excludes)
+	var released *releaseIndex
+	if commitCfg.SkipCherryPicks {
+		released = newReleaseIndex(r, index, cache, allTags, start.Hash)
+	}
+	tagCommits, stats, err := processCommits(commitCfg, beforeCommit, commits, allTags, excludes, released)
 	if err != nil {
 		return nil, FilterStats{}, err
 	}
+	if released != nil {
+		released.save()
+	}
 
 	return tagCommits, stats, nil
 }
@@ -174,10 +244,10 @@ func fetchCommitsBetween(
 func processCommits(
 	commitCfg CommitConfig,
 	beforeCommit *object.Commit,
-	afterCommit *object.Commit,
 	commits object.CommitIter,
 	allTags map[string]*TagMeta,
 	excludes []*regexp.Regexp,
+	released *releaseIndex,
 ) (*[]TagCommits, FilterStats, error) {
 	var tagCommits []TagCommits
 	var stats FilterStats
@@ -188,19 +258,22 @@ func processCommits(
 	}
 
 	var commitMessages []string
+	var commitDetails []CommitDetail
 
 	appendCurrentTag := func() {
 		if len(commitMessages) > 0 {
 			if commitCfg.UniqueOnly {
-				commitMessages = stringutil.Unique(commitMessages)
+				commitMessages, commitDetails = uniqueCommits(commitMessages, commitDetails)
 			}
 
 			tag := TagCommits{
 				TagMeta: currentTag,
 				Commits: commitMessages,
+				Details: commitDetails,
 			}
 			tagCommits = append(tagCommits, tag)
 			commitMessages = nil
+			commitDetails = nil
 		}
 	}
 
@@ -221,24 +294,48 @@ func processCommits(
 			currentTag = *tagCommit
 		}
 
-		// stop after appending tag commits for previous tag
-		if afterCommit != nil && c.Hash == afterCommit.Hash {
-			return storer.ErrStop
-		}
-
 		// check if we include tag commits in the changelog
 		if tagCommit != nil && commitCfg.ExcludeTagCommits {
 			return nil
 		}
 
 		stats.Considered++
+		if !commitCfg.Dates.includes(c) {
+			stats.OutsideDates++
+			return nil
+		}
 		longMessage := c.Message
 		if !shouldInclude(longMessage, excludes) {
 			stats.Excluded++
 			return nil
 		}
+		body := getMessageBody(longMessage)
+		if isSkipped(body) {
+			logrus.Tracef("skipping commit %s with changelog trailer", c.Hash)
+			stats.Skipped++
+			return nil
+		}
+		if released != nil {
+			copied, err := released.isReleasedCopy(c, currentTag.Date)
+			if err != nil {
+				return err
+			}
+			if copied {
+				stats.CherryPicks++
+				return nil
+			}
+		}
 		message := getShortMessage(longMessage)
 		commitMessages = append(commitMessages, message)
+		commitDetails = append(commitDetails, CommitDetail{
+			Hash:        c.Hash.String(),
+			Message:     message,
+			Author:      c.Author.Name,
+			AuthorEmail: c.Author.Email,
+			Date:        c.Author.When,
+			CommitDate:  c.Committer.When,
+			Body:        body,
+		})
 		return nil
 	})
 
@@ -252,6 +349,18 @@ func processCommits(
 	return &tagCommits, stats, nil
 }
 
+// uniqueCommits removes commits whose message duplicates an earlier one,
+// ignoring case, keeping the details aligned with the messages.
+func uniqueCommits(messages []string, details []CommitDetail) ([]string, []CommitDetail) {
+	var uniqueMessages []string
+	var uniqueDetails []CommitDetail
+	for _, i := range stringutil.UniqueIndices(messages) {
+		uniqueMessages = append(uniqueMessages, messages[i])
+		uniqueDetails = append(uniqueDetails, details[i])
+	}
+	return uniqueMessages, uniqueDetails
+}
+
 // listAllTags returns a map of tag hashes to tag metadata.
 func listAllTags(r *git.Repository) (map[string]*TagMeta, error) {
 	tags := make(map[string]*TagMeta)
@@ -284,6 +393,14 @@ func listAllTags(r *git.Repository) (map[string]*TagMeta, error) {
 	return tags, nil
 }
 
+// isSkipped returns true if the body of a commit message has a
+// 'Changelog: skip' trailer. If the trailer is given more than once,
+// the last one is used.
+func isSkipped(body string) bool {
+	values := convcommits.GetTrailerValues(body, ChangelogTrailer)
+	return len(values) > 0 && strings.EqualFold(values[len(values)-1], ChangelogSkipValue)
+}
+
 // shouldInclude returns true if the commit message does not match any of the excludes.
 func shouldInclude(message string, excludes []*regexp.Regexp) bool {
 	for _, exclude := range excludes {
@@ -304,3 +421,9 @@ func getShortMessage(message string) string {
 	}
 	return strings.TrimSpace(short)
 }
+
+// getMessageBody returns the lines of a commit message after the first.
+func getMessageBody(message string) string {
+	_, body, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(body)
+}
@@ -140,3 +140,41 @@ func Test_getShortMessage_withLeadingWhitespace(t *testing.T) {
 		t.Errorf("getShortMessage() = %v, want %v", got, want)
 	}
 }
+
+func Test_getMessageBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "subject only", message: "feat: add pagination\n", want: ""},
+		{name: "body and footer", message: "feat!: add pagination\n\nPages are 20 items.\n\nBREAKING CHANGE: lists are paged\n", want: "Pages are 20 items.\n\nBREAKING CHANGE: lists are paged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getMessageBody(tt.message); got != tt.want {
+				t.Errorf("getMessageBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isSkipped(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{name: "no trailers", body: "Some explanation.", want: false},
+		{name: "skip", body: "Internal clean-up.\n\nchangelog: Skip", want: true},
+		{name: "replaced text", body: "Changelog: Faster start-up", want: false},
+		{name: "last value wins", body: "Changelog: skip\nChangelog: Faster start-up", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSkipped(tt.body); got != tt.want {
+				t.Errorf("isSkipped() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"strings"
+	"time"
+)
+
+// DateType is the date of a commit used to filter commits by date.
+type DateType string
+
+const (
+	// DateCommitter is the committer date, when the commit landed on the
+	// branch, such as when a pull request was merged.
+	DateCommitter DateType = "committer"
+
+	// DateAuthor is the author date, when the change was first written.
+	DateAuthor DateType = "author"
+)
+
+// dateLayout is the layout of a date without a time.
+const dateLayout = "2006-01-02"
+
+// ParseDateType returns the date type with the given name.
+func ParseDateType(name string) (DateType, error) {
+	switch DateType(strings.ToLower(name)) {
+	case DateCommitter:
+		return DateCommitter, nil
+	case DateAuthor:
+		return DateAuthor, nil
+	default:
+		return "", fmt.Errorf("unsupported date type: %s, must be one of: %s, %s", name, DateCommitter, DateAuthor)
+	}
+}
+
+// DateFilter restricts the commits to those dated within a range.
+type DateFilter struct {
+	// Since is the earliest date included, or zero if there is no lower bound.
+	Since time.Time
+
+	// Until is the latest date included, or zero if there is no upper bound.
+	Until time.Time
+
+	// Type is the date of each commit that is compared, which is the
+	// committer date if not set.
+	Type DateType
+}
+
+// NewDateFilter returns a filter for commits dated from the since date to
+// the until date, inclusive. Each date is either a day, formatted as
+// YYYY-MM-DD, in the local time zone, or an RFC 3339 timestamp. A day
+// includes the whole day. Either date may be empty, for no bound.
+func NewDateFilter(since string, until string, dateType DateType) (DateFilter, error) {
+	filter := DateFilter{Type: dateType}
+	var err error
+	if since != "" {
+		if filter.Since, err = parseDate(since, false); err != nil {
+			return DateFilter{}, err
+		}
+	}
+	if until != "" {
+		if filter.Until, err = parseDate(until, true); err != nil {
+			return DateFilter{}, err
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		return DateFilter{}, fmt.Errorf("until date %s is before since date %s", until, since)
+	}
+	return filter, nil
+}
+
+// parseDate parses a day or an RFC 3339 timestamp. A day is the start of
+// the day, or the end of the day if endOfDay is true.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if day, err := time.ParseInLocation(dateLayout, value, time.Local); err == nil {
+		if endOfDay {
+			return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+		}
+		return day, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s, must be YYYY-MM-DD or an RFC 3339 timestamp", value)
+	}
+	return t, nil
+}
+
+// IsSet returns true if the filter has a lower or upper bound.
+func (f DateFilter) IsSet() bool {
+	return !f.Since.IsZero() || !f.Until.IsZero()
+}
+
+// includes returns true if the date of the commit is within the range.
+func (f DateFilter) includes(c *object.Commit) bool {
+	date := c.Committer.When
+	if f.Type == DateAuthor {
+		date = c.Author.When
+	}
+	if !f.Since.IsZero() && date.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && date.After(f.Until) {
+		return false
+	}
+	return true
+}
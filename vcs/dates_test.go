@@ -0,0 +1,130 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/release-tools/since/cfg"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewDateFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		since     string
+		until     string
+		wantSince time.Time
+		wantUntil time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "days",
+			since:     "2024-07-01",
+			until:     "2024-09-30",
+			wantSince: time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local),
+			wantUntil: time.Date(2024, 10, 1, 0, 0, 0, -1, time.Local),
+		},
+		{
+			name:      "timestamps",
+			since:     "2024-07-01T09:00:00Z",
+			wantSince: time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid date",
+			until:   "Q3",
+			wantErr: true,
+		},
+		{
+			name:    "until before since",
+			since:   "2024-09-30",
+			until:   "2024-07-01",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDateFilter(tt.since, tt.until, DateCommitter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewDateFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Since.Equal(tt.wantSince) || !got.Until.Equal(tt.wantUntil) {
+				t.Errorf("NewDateFilter() = %v to %v, want %v to %v", got.Since, got.Until, tt.wantSince, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestFetchCommitsByTag_dates(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each commit is authored a month before it is committed
+	for _, month := range []time.Month{time.June, time.July, time.August, time.September, time.October} {
+		committed := time.Date(2024, month, 15, 12, 0, 0, 0, time.UTC)
+		author := &object.Signature{Name: "user", Email: "user@example.com", When: committed.AddDate(0, -1, 0)}
+		committer := &object.Signature{Name: "user", Email: "user@example.com", When: committed}
+		_, err := w.Commit("feat: merged in "+month.String(), &git.CommitOptions{Author: author, Committer: committer, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		dateType DateType
+		want     []string
+	}{
+		{
+			name:     "committer date",
+			dateType: DateCommitter,
+			want:     []string{"feat: merged in September", "feat: merged in August", "feat: merged in July"},
+		},
+		{
+			name:     "author date",
+			dateType: DateAuthor,
+			want:     []string{"feat: merged in October", "feat: merged in September", "feat: merged in August"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dates, err := NewDateFilter("2024-07-01T00:00:00Z", "2024-09-30T23:59:59Z", tt.dateType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			commits, stats, err := FetchCommitsByTag(cfg.SinceConfig{}, CommitConfig{Dates: dates}, repoDir, "", "")
+			if err != nil {
+				t.Fatalf("FetchCommitsByTag() error = %v", err)
+			}
+			if got := FlattenCommits(commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FetchCommitsByTag() = %v, want %v", got, tt.want)
+			}
+			// the commit committed in June is before the range, so is not read
+			if stats.OutsideDates != 1 {
+				t.Errorf("FetchCommitsByTag() outside dates = %v, want 1", stats.OutsideDates)
+			}
+		})
+	}
+}
@@ -49,6 +49,19 @@ func TestFetchCommitsByTag(t *testing.T) {
 	if tagCommits == nil || len(*tagCommits) == 0 {
 		t.Fatal("FetchCommitsByTag() returned no tag commits")
 	}
+	for _, tag := range *tagCommits {
+		if len(tag.Details) != len(tag.Commits) {
+			t.Fatalf("FetchCommitsByTag() returned %d details for %d commits", len(tag.Details), len(tag.Commits))
+		}
+		for i, detail := range tag.Details {
+			if detail.Message != tag.Commits[i] || len(detail.Hash) != 40 || detail.Author == "" {
+				t.Errorf("FetchCommitsByTag() detail = %+v, want commit %q with hash and author", detail, tag.Commits[i])
+			}
+			if detail.CommitDate.IsZero() {
+				t.Errorf("FetchCommitsByTag() detail = %+v, want commit date", detail)
+			}
+		}
+	}
 }
 
 func TestFetchCommitMessages_withExcludes(t *testing.T) {
@@ -0,0 +1,121 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MailmapFile is the name of the file mapping author names and emails
+// to their canonical form, in the root of the repository.
+const MailmapFile = ".mailmap"
+
+var mailmapEmailRegex = regexp.MustCompile(`<([^>]*)>`)
+
+// Mailmap maps the names and emails of commit authors to their canonical
+// form, as described by gitmailmap(5).
+type Mailmap struct {
+	entries []mailmapEntry
+}
+
+type mailmapEntry struct {
+	properName  string
+	properEmail string
+	commitName  string
+	commitEmail string
+}
+
+// LoadMailmap loads the .mailmap file in the root of the repository. If the
+// file does not exist, an empty mailmap is returned.
+func LoadMailmap(repoPath string) (*Mailmap, error) {
+	file, err := os.Open(filepath.Join(repoPath, MailmapFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Mailmap{}, nil
+		}
+		return nil, fmt.Errorf("failed to read mailmap: %w", err)
+	}
+	defer file.Close()
+	return ParseMailmap(file)
+}
+
+// ParseMailmap parses the lines of a mailmap file. Lines that do not
+// contain an email are ignored.
+func ParseMailmap(r io.Reader) (*Mailmap, error) {
+	mailmap := &Mailmap{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		matches := mailmapEmailRegex.FindAllStringSubmatchIndex(line, 2)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var entry mailmapEntry
+		entry.properName = strings.TrimSpace(line[:matches[0][0]])
+		entry.properEmail = line[matches[0][2]:matches[0][3]]
+		if len(matches) == 2 {
+			entry.commitName = strings.TrimSpace(line[matches[0][1]:matches[1][0]])
+			entry.commitEmail = line[matches[1][2]:matches[1][3]]
+		} else {
+			// 'Proper Name <commit@email>' only replaces the name
+			entry.commitEmail = entry.properEmail
+			entry.properEmail = ""
+		}
+		mailmap.entries = append(mailmap.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mailmap: %w", err)
+	}
+	return mailmap, nil
+}
+
+// Resolve returns the canonical name and email for a commit author.
+// Names and emails are compared case-insensitively. Entries matching
+// both the name and email take precedence over those matching only
+// the email.
+func (m *Mailmap) Resolve(name string, email string) (string, string) {
+	var match *mailmapEntry
+	for i, entry := range m.entries {
+		if !strings.EqualFold(entry.commitEmail, email) {
+			continue
+		}
+		if entry.commitName == "" {
+			if match == nil || match.commitName == "" {
+				match = &m.entries[i]
+			}
+		} else if strings.EqualFold(entry.commitName, name) {
+			match = &m.entries[i]
+		}
+	}
+	if match == nil {
+		return name, email
+	}
+	if match.properName != "" {
+		name = match.properName
+	}
+	if match.properEmail != "" {
+		email = match.properEmail
+	}
+	return name, email
+}
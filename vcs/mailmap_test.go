@@ -0,0 +1,81 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMailmap_Resolve(t *testing.T) {
+	mailmap, err := ParseMailmap(strings.NewReader(`# comment
+Jane Doe <jane@example.com>
+<joe@example.com> <joe@old.example.com>
+Joe Bloggs <joe@example.com> <jbloggs@laptop.local>
+Jane Doe <jane@example.com> jd <jane@work.example.com>
+not an entry
+`))
+	if err != nil {
+		t.Fatalf("ParseMailmap() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		email     string
+		wantName  string
+		wantEmail string
+	}{
+		{name: "jane", email: "jane@example.com", wantName: "Jane Doe", wantEmail: "jane@example.com"},
+		{name: "Joe", email: "JOE@old.example.com", wantName: "Joe", wantEmail: "joe@example.com"},
+		{name: "joe", email: "jbloggs@laptop.local", wantName: "Joe Bloggs", wantEmail: "joe@example.com"},
+		{name: "jd", email: "jane@work.example.com", wantName: "Jane Doe", wantEmail: "jane@example.com"},
+		{name: "someone else", email: "jane@work.example.com", wantName: "someone else", wantEmail: "jane@work.example.com"},
+		{name: "Unmapped", email: "unmapped@example.com", wantName: "Unmapped", wantEmail: "unmapped@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.email, func(t *testing.T) {
+			gotName, gotEmail := mailmap.Resolve(tt.name, tt.email)
+			if gotName != tt.wantName || gotEmail != tt.wantEmail {
+				t.Errorf("Resolve() = %v, %v, want %v, %v", gotName, gotEmail, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestLoadMailmap(t *testing.T) {
+	repoDir := t.TempDir()
+	mailmap, err := LoadMailmap(repoDir)
+	if err != nil {
+		t.Fatalf("LoadMailmap() error = %v", err)
+	}
+	if name, _ := mailmap.Resolve("jane", "jane@example.com"); name != "jane" {
+		t.Errorf("Resolve() name = %v, want jane", name)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, MailmapFile), []byte("Jane Doe <jane@example.com>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mailmap, err = LoadMailmap(repoDir)
+	if err != nil {
+		t.Fatalf("LoadMailmap() error = %v", err)
+	}
+	if name, _ := mailmap.Resolve("jane", "jane@example.com"); name != "Jane Doe" {
+		t.Errorf("Resolve() name = %v, want Jane Doe", name)
+	}
+}
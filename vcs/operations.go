@@ -18,6 +18,9 @@ package vcs
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
@@ -31,16 +34,21 @@ type ReleaseMetadata struct {
 	RepoPath   string
 	Sha        string
 	VPrefix    bool
-}
 
-// CommitChangelog commits the changelog file.
-func CommitChangelog(repoPath string, changelogFile string, version string) (hash string, err error) {
-	// make relative to repo root
-	repoPathToChangelog := strings.TrimPrefix(changelogFile, repoPath)
-	if strings.HasPrefix(repoPathToChangelog, "/") || strings.HasPrefix(repoPathToChangelog, "\\") {
-		repoPathToChangelog = repoPathToChangelog[1:]
-	}
+	// Tag is the name of the release tag, including any v prefix.
+	Tag string
+
+	// Notes is the rendered changelog section for the release.
+	Notes string
+
+	// Fragments holds the paths of the changelog fragment files included
+	// in the release, which are removed by the release commit.
+	Fragments []string
+}
 
+// CommitChangelog commits the changelog file, and the removal of any of
+// the given files, such as changelog fragments included in the release.
+func CommitChangelog(repoPath string, changelogFile string, version string, removed ...string) (hash string, err error) {
 	r, err := git.PlainOpen(repoPath)
 	if err != nil {
 		return "", err
@@ -49,10 +57,19 @@ func CommitChangelog(repoPath string, changelogFile string, version string) (has
 	if err != nil {
 		return "", err
 	}
-	_, err = w.Add(repoPathToChangelog)
+	_, err = w.Add(relativeToRepo(repoPath, changelogFile))
 	if err != nil {
 		return "", err
 	}
+	for _, file := range removed {
+		if _, err := w.Remove(relativeToRepo(repoPath, file)); err != nil {
+			// files that were never committed are just deleted
+			logrus.Tracef("file %s is not tracked: %v", file, err)
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return "", fmt.Errorf("failed to remove %s: %w", file, err)
+			}
+		}
+	}
 	commit, err := w.Commit("build: release "+version, &git.CommitOptions{})
 	if err != nil {
 		return "", err
@@ -63,6 +80,47 @@ func CommitChangelog(repoPath string, changelogFile string, version string) (has
 	return sha, nil
 }
 
+// relativeToRepo returns the path of the file relative to the repository root.
+func relativeToRepo(repoPath string, file string) string {
+	if relative, err := filepath.Rel(repoPath, file); err == nil && !strings.HasPrefix(relative, "..") {
+		return filepath.ToSlash(relative)
+	}
+	relative := strings.TrimPrefix(file, repoPath)
+	if strings.HasPrefix(relative, "/") || strings.HasPrefix(relative, "\\") {
+		relative = relative[1:]
+	}
+	return relative
+}
+
+// PushRelease pushes the current branch and the release tag to the given
+// remote. It delegates to the git CLI so that the user's configured
+// credentials and transport are used.
+func PushRelease(repoPath string, remote string, tag string) error {
+	cmd := exec.Command("git", "-C", repoPath, "push", "--atomic", remote, "HEAD", "refs/tags/"+tag)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git push failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	logrus.Debugf("pushed %s to %s", tag, remote)
+	return nil
+}
+
+// MergeFile performs a line-based three-way merge of the files with the
+// git CLI, writing the result, including any conflict markers, to the
+// current file. It returns true if there were conflicts.
+func MergeFile(current string, base string, other string) (conflicts bool, err error) {
+	cmd := exec.Command("git", "merge-file", "-L", "ours", "-L", "base", "-L", "theirs", current, base, other)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		// the exit code is the number of conflicts, or negative on error
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+			return true, nil
+		}
+		return false, fmt.Errorf("git merge-file failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return false, nil
+}
+
 // GetHeadSha returns the SHA of the HEAD commit.
 func GetHeadSha(repoPath string) (string, error) {
 	r, err := git.PlainOpen(repoPath)
@@ -107,6 +107,65 @@ func TestCommitChangelog(t *testing.T) {
 	}
 }
 
+func TestCommitChangelog_removesFiles(t *testing.T) {
+	repoDir := createTestRepo(t)
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name = "user"
+	cfg.User.Email = "user@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(path.Join(repoDir, ".changes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tracked := path.Join(repoDir, ".changes", "tracked.md")
+	untracked := path.Join(repoDir, ".changes", "untracked.md")
+	for _, file := range []string{tracked, untracked} {
+		if err := os.WriteFile(file, []byte("A change.\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add(".changes/tracked.md"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Commit("docs: add fragment", &git.CommitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	changelogPath := path.Join(repoDir, "CHANGELOG.md")
+	if err := os.WriteFile(changelogPath, []byte("# Changelog\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CommitChangelog(repoDir, changelogPath, "1.0.0", tracked, untracked); err != nil {
+		t.Fatalf("CommitChangelog() error = %v", err)
+	}
+
+	for _, file := range []string{tracked, untracked} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("CommitChangelog() did not remove %s", file)
+		}
+	}
+	status, err := w.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsClean() {
+		t.Errorf("CommitChangelog() left changes uncommitted:\n%s", status)
+	}
+}
+
 func TestTagRelease(t *testing.T) {
 	repoDir := createTestRepo(t)
 
@@ -36,6 +36,39 @@ type TagMeta struct {
 type TagCommits struct {
 	TagMeta
 	Commits []string
+
+	// Details holds the metadata of each commit, in the same order as Commits.
+	// It may be empty if the commits were not read from a repository.
+	Details []CommitDetail
+}
+
+// CommitDetail describes a single commit.
+type CommitDetail struct {
+	Hash        string
+	Message     string
+	Author      string
+	AuthorEmail string
+	Date        time.Time
+
+	// CommitDate is the committer date, when the commit landed on the branch.
+	CommitDate time.Time
+
+	// Body is the commit message after the subject line, including
+	// any footers, such as 'BREAKING CHANGE: ...'.
+	Body string
+}
+
+// CommitDetails returns the metadata of each commit, in the same order as
+// Commits. If Details has not been populated, only the messages are set.
+func (t TagCommits) CommitDetails() []CommitDetail {
+	if len(t.Details) == len(t.Commits) {
+		return t.Details
+	}
+	details := make([]CommitDetail, len(t.Commits))
+	for i, message := range t.Commits {
+		details[i] = CommitDetail{Message: message}
+	}
+	return details
 }
 
 // Cache the earliest and latest tags in the repository.
@@ -67,6 +100,112 @@ func GetLatestTag(repoPath string, orderBy TagOrderBy) (string, error) {
 	return latestTag, nil
 }
 
+// GetPreviousTag returns the tag before the given tag, determined by the
+// given order, or an empty string if there is none.
+func GetPreviousTag(repoPath string, tag string, orderBy TagOrderBy) (string, error) {
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", err
+	}
+	tags, err := r.Tags()
+	if err != nil {
+		return "", err
+	}
+	var refs []*plumbing.Reference
+	commits := make(map[plumbing.ReferenceName]*object.Commit)
+	var current *plumbing.Reference
+	err = tags.ForEach(func(t *plumbing.Reference) error {
+		commitHash, err := getCommitHashForTag(t, r)
+		if err != nil {
+			return err
+		}
+		commit, err := r.CommitObject(commitHash)
+		if err != nil {
+			logrus.Tracef("failed to get commit object for tag %s: %v", t.Name().Short(), err)
+			return nil
+		}
+		refs = append(refs, t)
+		commits[t.Name()] = commit
+		if t.Name().Short() == tag {
+			current = t
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "", fmt.Errorf("tag not found: %s", tag)
+	}
+
+	var previous *plumbing.Reference
+	for _, t := range refs {
+		before, err := isTagBefore(t, commits[t.Name()], current, commits[current.Name()], orderBy)
+		if err != nil {
+			return "", err
+		}
+		if !before {
+			continue
+		}
+		if previous != nil {
+			if before, err = isTagBefore(previous, commits[previous.Name()], t, commits[t.Name()], orderBy); err != nil {
+				return "", err
+			}
+		}
+		if before {
+			previous = t
+		}
+	}
+	if previous == nil {
+		logrus.Tracef("no tag before %s", tag)
+		return "", nil
+	}
+	logrus.Tracef("tag before %s ordered by %s: %s", tag, orderBy, previous.Name().Short())
+	return previous.Name().Short(), nil
+}
+
+// IsTagInHistory returns true if the commit of the tag is reachable from
+// the commit of beforeTag, or from HEAD if beforeTag is empty.
+func IsTagInHistory(repoPath string, tag string, beforeTag string) (bool, error) {
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false, err
+	}
+	commit, err := getTagCommit(r, tag)
+	if err != nil {
+		return false, err
+	}
+	var head *object.Commit
+	if beforeTag != "" {
+		head, err = getTagCommit(r, beforeTag)
+	} else {
+		var ref *plumbing.Reference
+		if ref, err = r.Head(); err == nil {
+			head, err = r.CommitObject(ref.Hash())
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	if commit.Hash == head.Hash {
+		return true, nil
+	}
+	return commit.IsAncestor(head)
+}
+
+// getTagCommit returns the commit the named tag points to.
+func getTagCommit(r *git.Repository, tag string) (*object.Commit, error) {
+	ref, err := r.Tag(tag)
+	if err != nil {
+		return nil, fmt.Errorf("tag not found: %s: %w", tag, err)
+	}
+	hash, err := getCommitHashForTag(ref, r)
+	if err != nil {
+		return nil, err
+	}
+	return r.CommitObject(hash)
+}
+
 // getEndTag returns an end tag in the repository, of the given
 // end type, determined by the given order.
 func getEndTag(repoPath string, endType endTagType, orderBy TagOrderBy) (string, error) {
@@ -96,42 +235,16 @@ func getEndTag(repoPath string, endType endTagType, orderBy TagOrderBy) (string,
 			return nil
 		}
 
-		candidate := false
-		if candidateTag == nil {
-			candidate = true
-
-		} else {
-			switch orderBy {
-			case TagOrderAlphabetical:
-				switch endType {
-				case endTagLatest:
-					candidate = t.Name().Short() > candidateTag.Name().Short()
-				case endTagEarliest:
-					candidate = t.Name().Short() < candidateTag.Name().Short()
-				}
-
-			case TagOrderCommitDate:
-				var commitDateMatch bool
-				switch endType {
-				case endTagLatest:
-					commitDateMatch = candidateCommit == nil || commit.Committer.When.After(candidateCommit.Committer.When)
-				case endTagEarliest:
-					commitDateMatch = candidateCommit == nil || commit.Committer.When.Before(candidateCommit.Committer.When)
-				}
-				if commitDateMatch {
-					candidate = true
-				}
-
-			case TagOrderSemver:
-				switch endType {
-				case endTagLatest:
-					candidate = compareSemantically(t, candidateTag) > 0
-				case endTagEarliest:
-					candidate = compareSemantically(t, candidateTag) < 0
-				}
-
-			default:
-				return fmt.Errorf("unknown tag order by: %s", orderBy)
+		candidate := true
+		if candidateTag != nil {
+			switch endType {
+			case endTagLatest:
+				candidate, err = isTagBefore(candidateTag, candidateCommit, t, commit, orderBy)
+			case endTagEarliest:
+				candidate, err = isTagBefore(t, commit, candidateTag, candidateCommit, orderBy)
+			}
+			if err != nil {
+				return err
 			}
 		}
 
@@ -154,6 +267,21 @@ func getEndTag(repoPath string, endType endTagType, orderBy TagOrderBy) (string,
 	return tagName, nil
 }
 
+// isTagBefore returns true if tag a, pointing to commit aCommit, comes
+// before tag b, pointing to commit bCommit, in the given order.
+func isTagBefore(a *plumbing.Reference, aCommit *object.Commit, b *plumbing.Reference, bCommit *object.Commit, orderBy TagOrderBy) (bool, error) {
+	switch orderBy {
+	case TagOrderAlphabetical:
+		return a.Name().Short() < b.Name().Short(), nil
+	case TagOrderCommitDate:
+		return aCommit.Committer.When.Before(bCommit.Committer.When), nil
+	case TagOrderSemver:
+		return compareSemantically(a, b) < 0, nil
+	default:
+		return false, fmt.Errorf("unknown tag order by: %s", orderBy)
+	}
+}
+
 // getCommitHashForTag determines the SHA of the commit for the given tag,
 // handling both annotated and lightweight tags
 func getCommitHashForTag(t *plumbing.Reference, r *git.Repository) (commitHash plumbing.Hash, err error) {
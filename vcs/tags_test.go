@@ -206,6 +206,90 @@ func Test_getEndTag(t *testing.T) {
 	}
 }
 
+func TestGetPreviousTag(t *testing.T) {
+	repoDir := createTestRepo(t)
+
+	// a backport, released after 0.1.0, so semantic version and commit
+	// date order disagree
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{
+		Name:  "user",
+		Email: "user@example.com",
+		When:  time.UnixMilli(time.Now().UnixMilli() + 10000),
+	}
+	backport, err := w.Commit("backport", &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.CreateTag("0.0.5", backport, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tag     string
+		orderBy TagOrderBy
+		want    string
+		wantErr bool
+	}{
+		{name: "latest tag by semver", tag: "0.1.0", orderBy: TagOrderSemver, want: "0.0.5"},
+		{name: "backport by semver", tag: "0.0.5", orderBy: TagOrderSemver, want: "0.0.1"},
+		{name: "earliest tag by semver", tag: "0.0.1", orderBy: TagOrderSemver, want: ""},
+		{name: "latest tag by alphabetical sort", tag: "0.1.0", orderBy: TagOrderAlphabetical, want: "0.0.5"},
+		{name: "latest tag by date", tag: "0.0.5", orderBy: TagOrderCommitDate, want: "0.1.0"},
+		{name: "highest version by date", tag: "0.1.0", orderBy: TagOrderCommitDate, want: "0.0.1"},
+		{name: "earliest tag by date", tag: "0.0.1", orderBy: TagOrderCommitDate, want: ""},
+		{name: "missing tag", tag: "9.9.9", orderBy: TagOrderSemver, wantErr: true},
+		{name: "unknown order", tag: "0.1.0", orderBy: "random", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetPreviousTag(repoDir, tt.tag, tt.orderBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPreviousTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetPreviousTag() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTagInHistory(t *testing.T) {
+	repoDir := createTestRepo(t)
+	tests := []struct {
+		name      string
+		tag       string
+		beforeTag string
+		want      bool
+		wantErr   bool
+	}{
+		{name: "tag before HEAD", tag: "0.0.1", want: true},
+		{name: "tag before another tag", tag: "0.0.1", beforeTag: "0.1.0", want: true},
+		{name: "same tag", tag: "0.1.0", beforeTag: "0.1.0", want: true},
+		{name: "tag after another tag", tag: "0.1.0", beforeTag: "0.0.1", want: false},
+		{name: "missing tag", tag: "9.9.9", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsTagInHistory(repoDir, tt.tag, tt.beforeTag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsTagInHistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsTagInHistory() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // createTestRepo creates a test repo with two tags:
 // 0.0.1 and 0.1.0
 // The first tag is created 10 seconds before the second tag.
@@ -0,0 +1,326 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"container/heap"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraphfmt "github.com/go-git/go-git/v5/plumbing/format/commitgraph"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/sirupsen/logrus"
+	"io"
+	"time"
+)
+
+// commitGraphFile is the path of the commit-graph file, relative to the
+// git directory, written by 'git commit-graph write' or 'git gc'.
+const commitGraphFile = "objects/info/commit-graph"
+
+// openCommitNodeIndex returns an index of the commits in the repository,
+// backed by the commit-graph file if there is one, so that the history
+// can be walked without decoding each commit object. The returned closer
+// must be closed once the index is no longer needed.
+func openCommitNodeIndex(r *git.Repository) (commitgraph.CommitNodeIndex, io.Closer) {
+	objectIndex := commitgraph.NewObjectCommitNodeIndex(r.Storer)
+
+	storage, ok := r.Storer.(*filesystem.Storage)
+	if !ok {
+		return objectIndex, io.NopCloser(nil)
+	}
+	file, err := storage.Filesystem().Open(commitGraphFile)
+	if err != nil {
+		logrus.Tracef("no commit-graph file: %v", err)
+		return objectIndex, io.NopCloser(nil)
+	}
+	index, err := commitgraphfmt.OpenFileIndex(file)
+	if err != nil {
+		logrus.Debugf("ignoring invalid commit-graph file: %v", err)
+		_ = file.Close()
+		return objectIndex, io.NopCloser(nil)
+	}
+	logrus.Tracef("using commit-graph file")
+	return commitgraph.NewGraphCommitNodeIndex(index, r.Storer), file
+}
+
+// walkSlop is the number of commits walked after the last interesting
+// commit, and before a commit is returned, like the slop of 'git log',
+// so that a commit whose commit time is earlier than that of its parent
+// does not end the walk, or return a commit reachable from stop, too soon.
+const walkSlop = 5
+
+// walkCommits returns an iterator over the commits reachable from start,
+// but not from stop, newest commit first, like 'git log stop..start'. If
+// stop is nil, all the commits reachable from start are returned.
+//
+// The walk is bounded: commits are visited in order of commit time, and
+// the walk ends soon after every remaining commit is reachable from stop,
+// so only the history between the two commits is read, even if stop is
+// not an ancestor of start. Only the commits that are returned are decoded.
+func walkCommits(index commitgraph.CommitNodeIndex, start plumbing.Hash, stop *plumbing.Hash) (*commitWalker, error) {
+	var stops []plumbing.Hash
+	if stop != nil {
+		stops = append(stops, *stop)
+	}
+	return walkCommitsFrom(index, []plumbing.Hash{start}, stops)
+}
+
+// walkCommitsFrom returns an iterator over the commits reachable from any
+// of the starts, but from none of the stops, newest commit first, like
+// 'git log ^stop... start...'. The walk is bounded like walkCommits.
+func walkCommitsFrom(index commitgraph.CommitNodeIndex, starts []plumbing.Hash, stops []plumbing.Hash) (*commitWalker, error) {
+	w := &commitWalker{
+		index:         index,
+		queued:        make(map[plumbing.Hash]bool),
+		pending:       make(map[plumbing.Hash]bool),
+		uninteresting: make(map[plumbing.Hash]bool),
+		propagated:    make(map[plumbing.Hash]bool),
+		slop:          walkSlop,
+	}
+	for _, start := range starts {
+		if err := w.push(start, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, stop := range stops {
+		if err := w.push(stop, true); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// commitWalker walks the commit history in commit time order.
+type commitWalker struct {
+	index commitgraph.CommitNodeIndex
+	queue commitQueue
+
+	// pushed counts the commits queued, to order commits with the same
+	// commit time in the order they were found, so children come first.
+	pushed int
+
+	// queued holds the commits that have been queued as interesting.
+	queued map[plumbing.Hash]bool
+
+	// pending holds the queued commits that are not known to be reachable
+	// from the stop commit, and interesting counts them, so the walk knows
+	// when it is complete without scanning the queue.
+	pending     map[plumbing.Hash]bool
+	interesting int
+
+	// uninteresting holds the commits reachable from the stop commit
+	// that have been found so far.
+	uninteresting map[plumbing.Hash]bool
+
+	// propagated holds the uninteresting commits whose parents have
+	// been marked as uninteresting.
+	propagated map[plumbing.Hash]bool
+
+	// cutoff is the earliest commit time walked, or zero to walk the whole
+	// history. Older commits are treated as reachable from the stop commit.
+	cutoff time.Time
+
+	// held holds the interesting commits walked, but not returned yet.
+	held []heldCommit
+	// walked counts the commits taken from the queue.
+	walked int
+	// slop counts down the commits left to walk once no interesting
+	// commits are queued.
+	slop int
+	// done is true once the walk is complete.
+	done bool
+
+	// alone is true if no other interesting commit was queued when the
+	// last commit was returned, so the rest of the walk is the history
+	// of that commit alone.
+	alone bool
+}
+
+// heldCommit is an interesting commit waiting to be returned.
+type heldCommit struct {
+	node   commitgraph.CommitNode
+	alone  bool
+	walked int
+}
+
+// push queues the commit, if it has not been queued already.
+func (w *commitWalker) push(hash plumbing.Hash, uninteresting bool) error {
+	if uninteresting {
+		if !w.markUninteresting(hash) {
+			return nil
+		}
+	} else {
+		if w.queued[hash] {
+			return nil
+		}
+		w.queued[hash] = true
+		if !w.uninteresting[hash] {
+			w.pending[hash] = true
+			w.interesting++
+		}
+	}
+	node, err := w.index.Get(hash)
+	if err != nil {
+		return err
+	}
+	heap.Push(&w.queue, queuedCommit{node: node, seq: w.pushed})
+	w.pushed++
+	return nil
+}
+
+// markUninteresting marks the commit as reachable from the stop commit,
+// returning false if it already was.
+func (w *commitWalker) markUninteresting(hash plumbing.Hash) bool {
+	if w.uninteresting[hash] {
+		return false
+	}
+	w.uninteresting[hash] = true
+	if w.pending[hash] {
+		delete(w.pending, hash)
+		w.interesting--
+	}
+	return true
+}
+
+// Next returns the next commit that is reachable from the start commit,
+// but not the stop commit, or io.EOF when there are none. A commit is
+// returned once walkSlop more commits have been walked, in case one of
+// them shows that it is reachable from the stop commit after all.
+func (w *commitWalker) Next() (*object.Commit, error) {
+	for {
+		if len(w.held) > 0 && (w.done || w.walked-w.held[0].walked >= walkSlop) {
+			held := w.held[0]
+			w.held = w.held[1:]
+			if w.uninteresting[held.node.ID()] {
+				continue
+			}
+			w.alone = held.alone
+			return held.node.Commit()
+		}
+		if w.done {
+			return nil, io.EOF
+		}
+		if err := w.step(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// step walks the next commit in the queue, holding it if it is
+// interesting, or marking its parents as uninteresting if it is not.
+// Like 'git log', the walk continues until no interesting commits are
+// queued, and walkSlop more commits older than the last commit walked.
+func (w *commitWalker) step() error {
+	if w.queue.Len() == 0 || (w.interesting == 0 && w.slop == 0) {
+		w.done = true
+		return nil
+	}
+	node := heap.Pop(&w.queue).(queuedCommit).node
+	hash := node.ID()
+	w.walked++
+	if w.pending[hash] {
+		delete(w.pending, hash)
+		w.interesting--
+	}
+
+	uninteresting := w.uninteresting[hash]
+	if !uninteresting && !w.cutoff.IsZero() && node.CommitTime().Before(w.cutoff) {
+		w.markUninteresting(hash)
+		uninteresting = true
+	}
+	if !uninteresting || !w.propagated[hash] {
+		alone := w.interesting == 0
+		for _, parent := range node.ParentHashes() {
+			if err := w.push(parent, uninteresting); err != nil {
+				return err
+			}
+		}
+		if uninteresting {
+			w.propagated[hash] = true
+		} else {
+			w.held = append(w.held, heldCommit{node: node, alone: alone, walked: w.walked})
+		}
+	}
+
+	if w.interesting > 0 || (w.queue.Len() > 0 && !w.queue[0].node.CommitTime().Before(node.CommitTime())) {
+		w.slop = walkSlop
+	} else if w.slop > 0 {
+		w.slop--
+	}
+	return nil
+}
+
+// ForEach calls the callback for each commit, until the callback returns
+// an error, or storer.ErrStop to end the walk early.
+func (w *commitWalker) ForEach(cb func(*object.Commit) error) error {
+	return forEachCommit(w, cb)
+}
+
+func (w *commitWalker) Close() {}
+
+// forEachCommit calls the callback for each commit of the iterator, until
+// the callback returns an error, or storer.ErrStop to end the walk early.
+func forEachCommit(iter object.CommitIter, cb func(*object.Commit) error) error {
+	for {
+		c, err := iter.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := cb(c); err == storer.ErrStop {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+type queuedCommit struct {
+	node commitgraph.CommitNode
+	seq  int
+}
+
+// commitQueue is a priority queue of commits, newest commit time first,
+// then in the order they were queued.
+type commitQueue []queuedCommit
+
+func (q commitQueue) Len() int { return len(q) }
+
+func (q commitQueue) Less(i, j int) bool {
+	ti, tj := q[i].node.CommitTime(), q[j].node.CommitTime()
+	if !ti.Equal(tj) {
+		return ti.After(tj)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *commitQueue) Push(x any) { *q = append(*q, x.(queuedCommit)) }
+
+func (q *commitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	node := old[n-1]
+	*q = old[:n-1]
+	return node
+}
@@ -0,0 +1,308 @@
+/*
+Copyright © 2023 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcs
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/release-tools/since/cfg"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFetchCommitsByTag_divergedTag(t *testing.T) {
+	repoDir := createWalkTestRepo(t, 50)
+
+	for _, commitGraph := range []bool{false, true} {
+		t.Run(fmt.Sprintf("commit-graph %v", commitGraph), func(t *testing.T) {
+			if commitGraph {
+				writeCommitGraph(t, repoDir)
+			}
+			commits, _, err := FetchCommitsByTag(cfg.SinceConfig{}, CommitConfig{}, repoDir, "", "1.1.1")
+			if err != nil {
+				t.Fatalf("FetchCommitsByTag() error = %v", err)
+			}
+			if len(*commits) != 2 || (*commits)[0].Name != UnreleasedVersionName || (*commits)[1].Name != "1.2.0" {
+				t.Fatalf("FetchCommitsByTag() = %v, want unreleased and 1.2.0 commits", *commits)
+			}
+			var got []string
+			for _, tagCommits := range *commits {
+				got = append(got, tagCommits.Commits...)
+			}
+			if len(got) != 10 || got[0] != "feat: change 49" || got[9] != "feat: change 40" {
+				t.Errorf("FetchCommitsByTag() = %v, want the 10 commits after the fork", got)
+			}
+		})
+	}
+}
+
+func Test_walkCommits(t *testing.T) {
+	repoDir := createWalkTestRepo(t, 20)
+	r, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := r.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := r.Tag("1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash := root.Hash()
+	rootCommit, err := r.CommitObject(rootHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		stop   *plumbing.Hash
+		cutoff time.Time
+		want   int
+	}{
+		{name: "whole history", want: 21},
+		{name: "after root", stop: &rootHash, want: 20},
+		{name: "after head", stop: ptr(head.Hash()), want: 0},
+		{name: "since cutoff", cutoff: rootCommit.Committer.When.Add(10 * time.Minute), want: 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, closer := openCommitNodeIndex(r)
+			defer closer.Close()
+			iter, err := walkCommits(index, head.Hash(), tt.stop)
+			if err != nil {
+				t.Fatalf("walkCommits() error = %v", err)
+			}
+			iter.cutoff = tt.cutoff
+			var got int
+			var last time.Time
+			err = iter.ForEach(func(c *object.Commit) error {
+				if got > 0 && c.Committer.When.After(last) {
+					t.Errorf("walkCommits() returned %s out of commit time order", c.Hash)
+				}
+				last = c.Committer.When
+				got++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ForEach() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("walkCommits() returned %d commits, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_walkCommits_clockSkew checks a commit reachable from stop is not
+// returned, even if the path to it from stop goes through a commit with
+// an earlier commit time than the commit itself.
+func Test_walkCommits_clockSkew(t *testing.T) {
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	commit := func(message string, minutes int, parents ...plumbing.Hash) plumbing.Hash {
+		sig := object.Signature{Name: "user", Email: "user@example.com", When: base.Add(time.Duration(minutes) * time.Minute)}
+		c := &object.Commit{Author: sig, Committer: sig, Message: message, ParentHashes: parents}
+		obj := r.Storer.NewEncodedObject()
+		if err := c.Encode(obj); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := r.Storer.SetEncodedObject(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	initial := commit("chore: initial commit", 0)
+	shared := commit("feat: shared", 5, initial)
+	skewed := commit("fix: skewed clock", 1, shared)
+	stop := commit("chore: release", 10, skewed)
+	main := commit("feat: main", 8, shared)
+	head := commit("feat: head", 20, main)
+
+	index, closer := openCommitNodeIndex(r)
+	defer closer.Close()
+	iter, err := walkCommits(index, head, &stop)
+	if err != nil {
+		t.Fatalf("walkCommits() error = %v", err)
+	}
+	var got []string
+	err = iter.ForEach(func(c *object.Commit) error {
+		got = append(got, c.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+	if want := []string{"feat: head", "feat: main"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("walkCommits() = %v, want %v", got, want)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// BenchmarkWalk compares walking the history after a tag on a release
+// branch, forked 10 commits before HEAD, which is not an ancestor of HEAD.
+// An unbounded walk of the log never reaches the tag, so reads the whole
+// history; the bounded walk stops at the fork point.
+func BenchmarkWalk(b *testing.B) {
+	repoDir := createWalkTestRepo(b, 5000)
+	r, err := git.PlainOpen(repoDir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	head, err := r.Head()
+	if err != nil {
+		b.Fatal(err)
+	}
+	tag, err := r.Tag("1.1.1")
+	if err != nil {
+		b.Fatal(err)
+	}
+	stop := tag.Hash()
+
+	b.Run("log", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			commits, err := r.Log(&git.LogOptions{From: head.Hash()})
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = commits.ForEach(func(c *object.Commit) error {
+				if c.Hash == stop {
+					return storer.ErrStop
+				}
+				return nil
+			})
+		}
+	})
+	benchmarkBoundedWalk := func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			index, closer := openCommitNodeIndex(r)
+			commits, err := walkCommits(index, head.Hash(), &stop)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = commits.ForEach(func(c *object.Commit) error { return nil })
+			_ = closer.Close()
+		}
+	}
+	b.Run("bounded", benchmarkBoundedWalk)
+	b.Run("bounded-commit-graph", func(b *testing.B) {
+		writeCommitGraph(b, repoDir)
+		b.ResetTimer()
+		benchmarkBoundedWalk(b)
+	})
+}
+
+// BenchmarkFetchCommitsByTag fetches the commits after a tag near HEAD,
+// and after a tag on a release branch that is not an ancestor of HEAD.
+func BenchmarkFetchCommitsByTag(b *testing.B) {
+	repoDir := createWalkTestRepo(b, 5000)
+	for _, afterTag := range []string{"1.2.0", "1.1.1"} {
+		b.Run("after "+afterTag, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := FetchCommitsByTag(cfg.SinceConfig{}, CommitConfig{}, repoDir, "", afterTag); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// createWalkTestRepo creates a repository with a linear history of n commits
+// after an initial commit tagged 1.0.0. The commit 5 before HEAD is tagged
+// 1.2.0, and a release branch forked 10 commits before HEAD has a commit
+// tagged 1.1.1.
+func createWalkTestRepo(tb testing.TB, n int) string {
+	tb.Helper()
+	repoDir := tb.TempDir()
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	treeObj := r.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(treeObj); err != nil {
+		tb.Fatal(err)
+	}
+	treeHash, err := r.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	base := time.Now().Add(-time.Duration(n+1) * time.Minute).Truncate(time.Second)
+	commit := func(message string, when time.Time, parents ...plumbing.Hash) plumbing.Hash {
+		sig := object.Signature{Name: "user", Email: "user@example.com", When: when}
+		c := &object.Commit{Author: sig, Committer: sig, Message: message, TreeHash: treeHash, ParentHashes: parents}
+		obj := r.Storer.NewEncodedObject()
+		if err := c.Encode(obj); err != nil {
+			tb.Fatal(err)
+		}
+		hash, err := r.Storer.SetEncodedObject(obj)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return hash
+	}
+	tag := func(name string, hash plumbing.Hash) {
+		if _, err := r.CreateTag(name, hash, nil); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	parent := commit("chore: initial commit", base)
+	tag("1.0.0", parent)
+	for i := 0; i < n; i++ {
+		when := base.Add(time.Duration(i+1) * time.Minute)
+		if i == n-10 {
+			fork := commit("fix: backport", when.Add(-30*time.Second), parent)
+			tag("1.1.1", fork)
+		}
+		parent = commit(fmt.Sprintf("feat: change %d", i), when, parent)
+		if i == n-6 {
+			tag("1.2.0", parent)
+		}
+	}
+	if err := r.Storer.SetReference(plumbing.NewHashReference(plumbing.Master, parent)); err != nil {
+		tb.Fatal(err)
+	}
+	return repoDir
+}
+
+// writeCommitGraph writes the commit-graph file of the repository using
+// the git CLI, skipping the test if it is not available.
+func writeCommitGraph(tb testing.TB, repoDir string) {
+	tb.Helper()
+	cmd := exec.Command("git", "commit-graph", "write", "--reachable")
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		tb.Skipf("failed to write commit-graph: %v: %s", err, out)
+	}
+}